@@ -0,0 +1,202 @@
+package logruswrapper
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// QueueFullPolicy decides what happens to a line logged while the async
+// queue is full.
+type QueueFullPolicy int
+
+const (
+	// QueueBlock waits for room in the queue.
+	QueueBlock QueueFullPolicy = iota
+	// QueueDropNewest discards the line being logged.
+	QueueDropNewest
+	// QueueDropOldest discards the oldest queued line to make room.
+	QueueDropOldest
+)
+
+var (
+	async *asyncWriter
+
+	syncForTest atomic.Bool
+
+	// dropped counts lines discarded because the async queue was full.
+	dropped atomic.Uint64
+)
+
+// asyncWriter hands formatted lines to a background goroutine that writes
+// them to out.
+type asyncWriter struct {
+	outMu sync.Mutex
+	out   io.Writer
+
+	queue chan []byte
+	done  chan struct{}
+
+	pendingMu   sync.Mutex
+	pendingCond *sync.Cond
+	pending     int
+}
+
+// newAsyncWriter returns a writer that queues lines until start launches
+// the background goroutine.
+func newAsyncWriter(out io.Writer, queueSize int) *asyncWriter {
+	w := &asyncWriter{
+		out:   out,
+		queue: make(chan []byte, queueSize),
+		done:  make(chan struct{}),
+	}
+	w.pendingCond = sync.NewCond(&w.pendingMu)
+
+	return w
+}
+
+func (w *asyncWriter) start() {
+	go w.run()
+}
+
+func (w *asyncWriter) run() {
+	defer close(w.done)
+	for line := range w.queue {
+		w.outMu.Lock()
+		_, _ = w.out.Write(line)
+		w.outMu.Unlock()
+		w.addPending(-1)
+	}
+}
+
+func (w *asyncWriter) addPending(delta int) {
+	w.pendingMu.Lock()
+	defer w.pendingMu.Unlock()
+
+	w.pending += delta
+	if w.pending == 0 {
+		w.pendingCond.Broadcast()
+	}
+}
+
+func (w *asyncWriter) Write(p []byte) (int, error) {
+	if syncForTest.Load() {
+		w.flush()
+		w.outMu.Lock()
+		defer w.outMu.Unlock()
+		return w.out.Write(p)
+	}
+
+	// logrus reuses its buffer once Write returns.
+	line := append([]byte(nil), p...)
+
+	w.addPending(1)
+	switch loadOptions().queuePolicy {
+	case QueueDropNewest:
+		select {
+		case w.queue <- line:
+		default:
+			w.addPending(-1)
+			dropped.Add(1)
+		}
+	case QueueDropOldest:
+		for {
+			select {
+			case w.queue <- line:
+				return len(p), nil
+			default:
+			}
+			select {
+			case <-w.queue:
+				w.addPending(-1)
+				dropped.Add(1)
+			default:
+			}
+		}
+	default:
+		w.queue <- line
+	}
+
+	return len(p), nil
+}
+
+func (w *asyncWriter) setOut(out io.Writer) {
+	w.outMu.Lock()
+	defer w.outMu.Unlock()
+
+	w.out = out
+}
+
+func (w *asyncWriter) flush() {
+	w.pendingMu.Lock()
+	defer w.pendingMu.Unlock()
+
+	for w.pending > 0 {
+		w.pendingCond.Wait()
+	}
+}
+
+func (w *asyncWriter) close() {
+	close(w.queue)
+	<-w.done
+}
+
+// SetAsync moves writes off the calling goroutine onto a background writer
+// fed by a queue of queueSize lines. A queueSize <= 0 drains the queue and
+// returns to synchronous writes.
+func SetAsync(queueSize int) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	old := async
+	async = nil
+	if queueSize > 0 {
+		async = newAsyncWriter(output, queueSize)
+	}
+	// log.SetOutput waits for writes in flight, so once applyOutput returns
+	// nothing can send on the old queue any more and it is safe to close.
+	applyOutput()
+
+	if old != nil {
+		old.close()
+	}
+	// Start the new writer only after the old queue has drained so lines
+	// keep their order across the switch.
+	if async != nil {
+		async.start()
+	}
+}
+
+func SetAsyncPolicy(policy QueueFullPolicy) {
+	updateOptions(func(o *options) { o.queuePolicy = policy })
+}
+
+// SetSyncForTest makes async mode write each line before the log call
+// returns, so tests can assert on output without calling Flush. It is meant
+// for tests only: it gives up the benefits of async logging entirely.
+func SetSyncForTest(enabled bool) {
+	syncForTest.Store(enabled)
+}
+
+// DroppedCount returns how many lines have been discarded because the async
+// queue was full.
+func DroppedCount() uint64 {
+	return dropped.Load()
+}
+
+// Flush blocks until every queued line has been written.
+func Flush() {
+	mu.Lock()
+	w := async
+	mu.Unlock()
+
+	if w != nil {
+		w.flush()
+	}
+}
+
+// Close drains the async queue, stops the background writer and restores
+// synchronous writes.
+func Close() {
+	SetAsync(0)
+}
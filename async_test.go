@@ -0,0 +1,250 @@
+package logruswrapper
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// gatedWriter blocks every write until release is closed and signals
+// started on the first write.
+type gatedWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func newGatedWriter() *gatedWriter {
+	return &gatedWriter{started: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { close(w.started) })
+	<-w.release
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *gatedWriter) messages() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var msgs []string
+	for _, line := range strings.Split(strings.TrimSpace(w.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		_, msg, _, _ := ParseCompactLine(line)
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+func TestSetAsync_PreservesOrder(t *testing.T) {
+	captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	buf := &bytes.Buffer{}
+	SetOutput(buf)
+	SetAsync(16)
+	defer Close()
+
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		Info(ctx, fmt.Sprintf("line %d", i), nil)
+	}
+	Flush()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 10 {
+		t.Fatalf("expected 10 lines after Flush, got %d", len(lines))
+	}
+	for i, line := range lines {
+		if !strings.Contains(line, fmt.Sprintf(`"msg":"line %d"`, i)) {
+			t.Errorf("expected line %d in position %d, got %s", i, i, line)
+		}
+	}
+}
+
+func TestSetAsync_DropPolicies(t *testing.T) {
+	tests := []struct {
+		policy QueueFullPolicy
+		want   []string
+	}{
+		{QueueDropNewest, []string{"m1", "m2", "m3"}},
+		{QueueDropOldest, []string{"m1", "m5", "m6"}},
+	}
+
+	for _, tt := range tests {
+		captureOutput()
+		setLevel(logrus.InfoLevel)
+		log.SetFormatter(compactFormatter{})
+
+		w := newGatedWriter()
+		SetOutput(w)
+		SetAsyncPolicy(tt.policy)
+		SetAsync(2)
+
+		ctx := context.Background()
+		Info(ctx, "m1", nil)
+		<-w.started // m1 is now held by the background writer
+		for i := 2; i <= 6; i++ {
+			Info(ctx, fmt.Sprintf("m%d", i), nil)
+		}
+		close(w.release)
+		Close()
+
+		got := w.messages()
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("policy %d: expected %v, got %v", tt.policy, tt.want, got)
+		}
+
+		SetAsyncPolicy(QueueBlock)
+		restoreOutput()
+	}
+}
+
+func TestSetSyncForTest_WritesWithoutFlush(t *testing.T) {
+	captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	buf := &lockedBuffer{}
+	SetOutput(buf)
+	SetAsync(16)
+	defer Close()
+
+	SetSyncForTest(true)
+	defer SetSyncForTest(false)
+
+	Info(context.Background(), "immediately visible", nil)
+
+	if !strings.Contains(buf.String(), "immediately visible") {
+		t.Errorf("expected the line to be written before Info returned, got %q", buf.String())
+	}
+}
+
+func TestDroppedCount_CountsDiscardedLines(t *testing.T) {
+	for _, policy := range []QueueFullPolicy{QueueDropNewest, QueueDropOldest} {
+		captureOutput()
+		setLevel(logrus.InfoLevel)
+
+		w := newGatedWriter()
+		SetOutput(w)
+		SetAsyncPolicy(policy)
+		SetAsync(2)
+
+		before := DroppedCount()
+		ctx := context.Background()
+		Info(ctx, "held", nil)
+		<-w.started
+		for i := 0; i < 7; i++ {
+			Info(ctx, "queued", nil)
+		}
+
+		if got := DroppedCount() - before; got != 5 {
+			t.Errorf("policy %d: expected 5 dropped lines, got %d", policy, got)
+		}
+
+		close(w.release)
+		Close()
+		SetAsyncPolicy(QueueBlock)
+		restoreOutput()
+	}
+}
+
+func TestClose_WhileLogging(t *testing.T) {
+	captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	buf := &lockedBuffer{}
+	SetOutput(buf)
+
+	ctx := context.Background()
+	for round := 0; round < 20; round++ {
+		SetAsync(1)
+
+		var wg sync.WaitGroup
+		stop := make(chan struct{})
+		for g := 0; g < 4; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					select {
+					case <-stop:
+						return
+					default:
+						Info(ctx, "concurrent", nil)
+					}
+				}
+			}()
+		}
+
+		Close()
+		close(stop)
+		wg.Wait()
+	}
+
+	if !strings.Contains(buf.String(), `"msg":"concurrent"`) {
+		t.Error("expected lines logged around Close to be written")
+	}
+}
+
+func TestFatal_FlushesAsyncQueueBeforeExit(t *testing.T) {
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	buf := &lockedBuffer{}
+	SetOutput(buf)
+	log.SetFormatter(&logrus.JSONFormatter{})
+	SetAsync(256)
+	defer SetAsync(0)
+
+	var atExit string
+	log.ExitFunc = func(int) { atExit = buf.String() }
+	defer func() { log.ExitFunc = nil }()
+
+	for i := 0; i < 100; i++ {
+		Info(context.Background(), fmt.Sprintf("line %d", i), nil)
+	}
+	Fatal(context.Background(), "cannot continue", nil)
+
+	if !strings.Contains(atExit, `"msg":"line 99"`) || !strings.Contains(atExit, `"msg":"cannot continue"`) {
+		t.Errorf("expected queued lines and the fatal line to be written before exit, got %d bytes", len(atExit))
+	}
+}
+
+func TestLog_PanicFlushesAsyncQueue(t *testing.T) {
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	buf := &lockedBuffer{}
+	SetOutput(buf)
+	log.SetFormatter(&logrus.JSONFormatter{})
+	SetAsync(256)
+	defer SetAsync(0)
+
+	for i := 0; i < 100; i++ {
+		Info(context.Background(), fmt.Sprintf("line %d", i), nil)
+	}
+	func() {
+		defer func() { _ = recover() }()
+		Log(context.Background(), logrus.PanicLevel, "giving up", nil)
+	}()
+
+	if out := buf.String(); !strings.Contains(out, `"msg":"giving up"`) {
+		t.Errorf("expected the panic line to be written before unwinding, got %d bytes", len(out))
+	}
+}
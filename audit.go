@@ -0,0 +1,44 @@
+package logruswrapper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+var ErrMissingAuditField = errors.New("missing required audit field")
+
+var auditRequiredFields = []string{"actor", "resource", "outcome"}
+
+// Audit emits an Info line marked "audit":true. The action plus the actor,
+// resource and outcome fields are mandatory; nothing is logged if any is missing.
+// Audit lines are compliance records, so they are written whatever the level
+// and are never dropped by per-request sampling.
+func Audit(ctx context.Context, action string, fields Fields) error {
+	var missing []string
+	if action == "" {
+		missing = append(missing, "action")
+	}
+	for _, key := range auditRequiredFields {
+		if v, ok := fields[key]; !ok || v == nil || v == "" {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrMissingAuditField, strings.Join(missing, ", "))
+	}
+	auditFields := Fields{}
+	for k, v := range fields {
+		auditFields[k] = v
+	}
+	auditFields["action"] = action
+	auditFields["audit"] = true
+
+	callerFields := getCaller(logrus.InfoLevel)
+	generateLogger(ctx, &auditFields).WithFields(*callerFields).Info("audit")
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package logruswrapper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestAudit_Success(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	err := Audit(context.Background(), "user.delete", Fields{
+		"actor":    "admin@example.com",
+		"resource": "user/42",
+		"outcome":  "success",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	if entry["audit"] != true {
+		t.Errorf("expected audit=true, got %v", entry["audit"])
+	}
+	if entry["action"] != "user.delete" {
+		t.Errorf("expected action 'user.delete', got %v", entry["action"])
+	}
+	if entry["actor"] != "admin@example.com" {
+		t.Errorf("expected actor field, got %v", entry["actor"])
+	}
+}
+
+func TestAudit_WrittenAboveInfo(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.WarnLevel)
+	defer setLevel(logrus.InfoLevel)
+
+	err := Audit(context.Background(), "user.delete", Fields{
+		"actor":    "admin@example.com",
+		"resource": "user/42",
+		"outcome":  "success",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(buf.String(), `"audit":true`) {
+		t.Errorf("expected the audit line to be written at Warn level, got %q", buf.String())
+	}
+}
+
+func TestAudit_MissingRequiredFields(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	err := Audit(context.Background(), "user.delete", Fields{"actor": "admin@example.com"})
+	if !errors.Is(err, ErrMissingAuditField) {
+		t.Fatalf("expected ErrMissingAuditField, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "resource") || !strings.Contains(err.Error(), "outcome") {
+		t.Errorf("expected error to name missing fields, got %q", err.Error())
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected nothing logged on rejection, got: %s", buf.String())
+	}
+}
@@ -0,0 +1,57 @@
+package logruswrapper
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// NewCircuitBreakerWriter wraps a (typically remote) sink so that after
+// failThreshold consecutive write errors, lines are discarded for cooldown
+// instead of hitting the failing sink. After the cooldown one write is let
+// through as a probe; success closes the circuit, failure reopens it.
+func NewCircuitBreakerWriter(inner io.Writer, failThreshold int, cooldown time.Duration) io.Writer {
+	if failThreshold < 1 {
+		failThreshold = 1
+	}
+
+	return &circuitBreakerWriter{inner: inner, threshold: failThreshold, cooldown: cooldown}
+}
+
+type circuitBreakerWriter struct {
+	inner     io.Writer
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	failures int
+	open     bool
+	openedAt time.Time
+}
+
+func (w *circuitBreakerWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.open && now().Sub(w.openedAt) < w.cooldown {
+		return len(p), nil
+	}
+
+	n, err := w.inner.Write(p)
+	if err == nil && n != len(p) {
+		err = io.ErrShortWrite
+	}
+	if err != nil {
+		w.failures++
+		if w.open || w.failures >= w.threshold {
+			w.open = true
+			w.openedAt = now()
+		}
+		return n, err
+	}
+
+	w.failures = 0
+	w.open = false
+
+	return n, nil
+}
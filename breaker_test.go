@@ -0,0 +1,64 @@
+package logruswrapper
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+// flakyWriter fails its first failures writes and records the rest.
+type flakyWriter struct {
+	failures int
+	calls    int
+	buf      bytes.Buffer
+}
+
+func (w *flakyWriter) Write(p []byte) (int, error) {
+	w.calls++
+	if w.calls <= w.failures {
+		return 0, errors.New("sink down")
+	}
+
+	return w.buf.Write(p)
+}
+
+func TestCircuitBreakerWriter_OpensAndRecovers(t *testing.T) {
+	clock := time.Unix(1000, 0)
+	now = func() time.Time { return clock }
+	defer func() { now = time.Now }()
+
+	inner := &flakyWriter{failures: 4}
+	w := NewCircuitBreakerWriter(inner, 3, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		if _, err := w.Write([]byte("x\n")); err == nil {
+			t.Fatalf("expected write %d to fail", i)
+		}
+	}
+
+	if _, err := w.Write([]byte("dropped\n")); err != nil {
+		t.Errorf("expected open circuit to discard silently, got %v", err)
+	}
+	if inner.calls != 3 {
+		t.Errorf("expected no writes to reach the sink while open, got %d calls", inner.calls)
+	}
+
+	clock = clock.Add(time.Minute)
+	if _, err := w.Write([]byte("probe\n")); err == nil {
+		t.Error("expected failed probe to return its error")
+	}
+	w.Write([]byte("dropped again\n"))
+	if inner.calls != 4 {
+		t.Errorf("expected failed probe to reopen the circuit, got %d calls", inner.calls)
+	}
+
+	clock = clock.Add(time.Minute)
+	if _, err := w.Write([]byte("recovered\n")); err != nil {
+		t.Fatalf("expected successful probe, got %v", err)
+	}
+	w.Write([]byte("flowing\n"))
+	if got := inner.buf.String(); got != "recovered\nflowing\n" {
+		t.Errorf("expected writes to flow after recovery, got %q", got)
+	}
+}
@@ -0,0 +1,28 @@
+package logruswrapper
+
+import (
+	"runtime/debug"
+)
+
+var readBuildInfo = debug.ReadBuildInfo
+
+// SetBuildInfo attaches "version" and "commit" fields to every entry. Empty
+// arguments fall back to the module version and VCS revision embedded in the
+// binary, when available.
+func SetBuildInfo(version, commit string) {
+	if info, ok := readBuildInfo(); ok {
+		if version == "" && info.Main.Version != "(devel)" {
+			version = info.Main.Version
+		}
+		for _, setting := range info.Settings {
+			if commit == "" && setting.Key == "vcs.revision" {
+				commit = setting.Value
+			}
+		}
+	}
+
+	updateOptions(func(o *options) {
+		o.version = version
+		o.commit = commit
+	})
+}
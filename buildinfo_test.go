@@ -0,0 +1,59 @@
+package logruswrapper
+
+import (
+	"context"
+	"encoding/json"
+	"runtime/debug"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func stubBuildInfo(t *testing.T) {
+	t.Helper()
+	readBuildInfo = func() (*debug.BuildInfo, bool) {
+		return &debug.BuildInfo{
+			Main:     debug.Module{Version: "v1.4.0"},
+			Settings: []debug.BuildSetting{{Key: "vcs.revision", Value: "abc123"}},
+		}, true
+	}
+	t.Cleanup(func() {
+		readBuildInfo = debug.ReadBuildInfo
+		updateOptions(func(o *options) { o.version, o.commit = "", "" })
+	})
+}
+
+func logBuildInfoLine(t *testing.T) map[string]interface{} {
+	t.Helper()
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	Info(context.Background(), "build info", nil)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+	return entry
+}
+
+func TestSetBuildInfo_FallsBackToBuildInfo(t *testing.T) {
+	stubBuildInfo(t)
+	SetBuildInfo("", "")
+
+	entry := logBuildInfoLine(t)
+	if entry["version"] != "v1.4.0" || entry["commit"] != "abc123" {
+		t.Errorf("expected version and commit from build info, got %v / %v", entry["version"], entry["commit"])
+	}
+}
+
+func TestSetBuildInfo_ExplicitValuesWin(t *testing.T) {
+	stubBuildInfo(t)
+	SetBuildInfo("v2.0.0-rc1", "deadbeef")
+
+	entry := logBuildInfoLine(t)
+	if entry["version"] != "v2.0.0-rc1" || entry["commit"] != "deadbeef" {
+		t.Errorf("expected explicit version and commit, got %v / %v", entry["version"], entry["commit"])
+	}
+}
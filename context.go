@@ -0,0 +1,243 @@
+package logruswrapper
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+type contextFieldsKey struct{}
+
+// ContextWithFields returns a copy of ctx carrying fields, merged over any
+// fields already stored on ctx. Later keys win on collision. Every log call
+// made with the returned context includes them.
+func ContextWithFields(ctx context.Context, fields Fields) context.Context {
+	parent := contextFields(ctx)
+	merged := make(Fields, len(parent)+len(fields))
+	for k, v := range parent {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+
+	return context.WithValue(ctx, contextFieldsKey{}, merged)
+}
+
+func contextFields(ctx context.Context) Fields {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(contextFieldsKey{}).(Fields)
+
+	return fields
+}
+
+const requestIDKey = "request_id"
+
+// EnsureRequestID returns the request ID already stored in ctx's fields, or
+// generates a new UUID and stores it so every line logged with the returned
+// context carries it.
+func EnsureRequestID(ctx context.Context) (context.Context, string) {
+	if id, ok := contextFields(ctx)[requestIDKey].(string); ok && id != "" {
+		return ctx, id
+	}
+
+	id := newUUID()
+
+	return ContextWithFields(ctx, Fields{requestIDKey: id}), id
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+type contextLevelKey struct{}
+
+// WithLevel returns a copy of ctx whose log calls use level instead of the
+// global level, e.g. to get Debug output for one suspicious request.
+func WithLevel(ctx context.Context, level logrus.Level) context.Context {
+	return context.WithValue(ctx, contextLevelKey{}, level)
+}
+
+// levelFor returns the level that applies to a log call made with ctx from
+// the call site pc: the one scoped on ctx, else the one set for the call
+// site's package with SetPackageLevel, else the global level.
+func levelFor(ctx context.Context, pc uintptr) logrus.Level {
+	if ctx != nil {
+		if level, ok := ctx.Value(contextLevelKey{}).(logrus.Level); ok {
+			return level
+		}
+	}
+	if level, ok := packageLevel(pc); ok {
+		return level
+	}
+
+	return currentLevel()
+}
+
+type packageLevelOverride struct {
+	prefix string
+	level  logrus.Level
+}
+
+// SetPackageLevel logs calls made from functions under pkgPrefix (e.g.
+// "github.com/org/repo/db") at level instead of the global level. A level
+// scoped on the context with WithLevel still takes precedence. When several
+// prefixes match, the longest wins.
+func SetPackageLevel(pkgPrefix string, level logrus.Level) {
+	updateOptions(func(o *options) {
+		overrides := make([]packageLevelOverride, 0, len(o.packageLevels)+1)
+		for _, p := range o.packageLevels {
+			if p.prefix != pkgPrefix {
+				overrides = append(overrides, p)
+			}
+		}
+		overrides = append(overrides, packageLevelOverride{prefix: pkgPrefix, level: level})
+		sort.Slice(overrides, func(i, j int) bool {
+			return len(overrides[i].prefix) > len(overrides[j].prefix)
+		})
+		o.packageLevels = overrides
+	})
+}
+
+// ClearPackageLevel removes the SetPackageLevel override for pkgPrefix, so
+// calls from that package use the global level again.
+func ClearPackageLevel(pkgPrefix string) {
+	updateOptions(func(o *options) {
+		overrides := make([]packageLevelOverride, 0, len(o.packageLevels))
+		for _, p := range o.packageLevels {
+			if p.prefix != pkgPrefix {
+				overrides = append(overrides, p)
+			}
+		}
+		o.packageLevels = overrides
+	})
+}
+
+// callerPC returns the PC of the function skip frames above the caller of
+// callerPC, as with runtime.Caller. It returns 0 without walking the stack
+// when no SetPackageLevel override is set, since that is its only use.
+func callerPC(skip int) uintptr {
+	if len(loadOptions().packageLevels) == 0 {
+		return 0
+	}
+
+	var pcs [1]uintptr
+	if runtime.Callers(skip+2, pcs[:]) == 0 {
+		return 0
+	}
+
+	return pcs[0]
+}
+
+// packageLevel returns the SetPackageLevel override for the call site pc.
+func packageLevel(pc uintptr) (logrus.Level, bool) {
+	overrides := loadOptions().packageLevels
+	if pc == 0 || len(overrides) == 0 {
+		return 0, false
+	}
+
+	name := callSiteFunction(pc)
+	for _, p := range overrides {
+		if name == p.prefix || strings.HasPrefix(name, p.prefix) && strings.ContainsRune("./", rune(name[len(p.prefix)])) {
+			return p.level, true
+		}
+	}
+
+	return 0, false
+}
+
+// callSiteFunction returns the name of the function at pc. A log/slog method
+// inlined into the caller is skipped so slog call sites resolve to the
+// code that called slog.
+func callSiteFunction(pc uintptr) string {
+	frames := runtime.CallersFrames([]uintptr{pc})
+	for {
+		frame, more := frames.Next()
+		if !more || !strings.HasPrefix(frame.Function, "log/slog.") {
+			return frame.Function
+		}
+	}
+}
+
+// IDExtractor pulls correlation fields (request, trace or tenant IDs...)
+// out of a context. Registered extractors run for every log line.
+type IDExtractor interface {
+	Extract(ctx context.Context) Fields
+}
+
+// ExtractorFunc adapts a plain function to IDExtractor.
+type ExtractorFunc func(ctx context.Context) Fields
+
+func (f ExtractorFunc) Extract(ctx context.Context) Fields {
+	return f(ctx)
+}
+
+// RegisterExtractor adds e to the extractors run on every log line. Fields
+// from later extractors win over earlier ones; per-call fields win over all.
+func RegisterExtractor(e IDExtractor) {
+	updateOptions(func(o *options) {
+		o.extractors = append(append([]IDExtractor(nil), o.extractors...), e)
+	})
+}
+
+func extractFields(ctx context.Context) Fields {
+	extractors := loadOptions().extractors
+	if ctx == nil || len(extractors) == 0 {
+		return nil
+	}
+
+	var fields Fields
+	for _, e := range extractors {
+		for k, v := range e.Extract(ctx) {
+			if fields == nil {
+				fields = Fields{}
+			}
+			fields[k] = v
+		}
+	}
+
+	return fields
+}
+
+// absentContextValue is what DumpContext reports for keys ctx has no value
+// for.
+const absentContextValue = "<absent>"
+
+// DumpContext logs at Debug the values ctx holds for keys under a "context"
+// field, keyed by their printed form. Context values cannot be enumerated,
+// so only the given keys are looked up; missing ones are reported as
+// "<absent>".
+func DumpContext(ctx context.Context, keys ...interface{}) {
+	if !enabled(ctx, logrus.DebugLevel, "context dump") {
+		return
+	}
+
+	values := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		v := ctx.Value(key)
+		switch {
+		case v == nil:
+			v = absentContextValue
+		case !jsonSafe(v):
+			v = fmt.Sprintf("%v", v)
+		}
+		values[fmt.Sprintf("%v", key)] = v
+	}
+
+	fields := Fields{"context": values}
+	callerFields := getCaller(logrus.DebugLevel)
+	generateLogger(ctx, &fields).WithFields(*callerFields).Debug("context dump")
+}
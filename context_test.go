@@ -0,0 +1,307 @@
+package logruswrapper
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestContextWithFields_AccumulatesAcrossLayers(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	ctx := ContextWithFields(context.Background(), Fields{"a": 1, "shared": "outer"})
+	ctx = ContextWithFields(ctx, Fields{"b": 2, "shared": "inner"})
+
+	Info(ctx, "layered", nil)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	if entry["a"] != float64(1) {
+		t.Errorf("expected a=1 from the outer layer, got %v", entry["a"])
+	}
+	if entry["b"] != float64(2) {
+		t.Errorf("expected b=2 from the inner layer, got %v", entry["b"])
+	}
+	if entry["shared"] != "inner" {
+		t.Errorf("expected inner layer to win on collision, got %v", entry["shared"])
+	}
+}
+
+func TestContextWithFields_ParentUnaffected(t *testing.T) {
+	parent := ContextWithFields(context.Background(), Fields{"a": 1})
+	ContextWithFields(parent, Fields{"b": 2})
+
+	if _, ok := contextFields(parent)["b"]; ok {
+		t.Error("expected parent context fields to be unaffected by a child layer")
+	}
+}
+
+func TestContextWithFields_CallFieldsWin(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	ctx := ContextWithFields(context.Background(), Fields{"user": "from-ctx"})
+	fields := Fields{"user": "explicit"}
+	Info(ctx, "override", &fields)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	if entry["user"] != "explicit" {
+		t.Errorf("expected per-call field to win over context field, got %v", entry["user"])
+	}
+}
+
+func TestEnsureRequestID_GeneratesWhenAbsent(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	ctx, id := EnsureRequestID(context.Background())
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(id) {
+		t.Fatalf("expected a v4 UUID, got %q", id)
+	}
+
+	Info(ctx, "with request id", nil)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+	if entry["request_id"] != id {
+		t.Errorf("expected request_id %q on the line, got %v", id, entry["request_id"])
+	}
+}
+
+func TestEnsureRequestID_PreservesExisting(t *testing.T) {
+	ctx := ContextWithFields(context.Background(), Fields{"request_id": "req-existing"})
+
+	got, id := EnsureRequestID(ctx)
+	if id != "req-existing" {
+		t.Errorf("expected existing request ID to be preserved, got %q", id)
+	}
+	if got != ctx {
+		t.Error("expected the context to be returned unchanged")
+	}
+}
+
+func TestWithLevel_OverridesGlobalLevel(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	Debug(context.Background(), "outside scope", nil)
+	if buf.Len() != 0 {
+		t.Fatalf("expected Debug to be suppressed outside the scope, got: %s", buf.String())
+	}
+
+	ctx := WithLevel(context.Background(), logrus.DebugLevel)
+	Debug(ctx, "inside scope", nil)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+	if entry["msg"] != "inside scope" || entry["level"] != "debug" {
+		t.Errorf("expected Debug line within the scope, got %v", entry)
+	}
+	if currentLevel() != logrus.InfoLevel {
+		t.Errorf("expected global level to stay %v, got %v", logrus.InfoLevel, currentLevel())
+	}
+}
+
+func TestWithLevel_CanSuppress(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	ctx := WithLevel(context.Background(), logrus.ErrorLevel)
+	Info(ctx, "quiet scope", nil)
+
+	if buf.Len() != 0 {
+		t.Errorf("expected Info to be suppressed within an Error scope, got: %s", buf.String())
+	}
+}
+
+func TestWithLevel_SharesLoggerWithPlainContext(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	scoped := WithLevel(context.Background(), logrus.DebugLevel)
+	var wg sync.WaitGroup
+	for _, ctx := range []context.Context{scoped, context.Background()} {
+		wg.Add(1)
+		go func(ctx context.Context) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				Info(ctx, "concurrent", nil)
+			}
+		}(ctx)
+	}
+	wg.Wait()
+
+	if n := strings.Count(buf.String(), "\n"); n != 200 {
+		t.Errorf("expected 200 whole lines, got %d", n)
+	}
+}
+
+type tenantKey struct{}
+
+type tenantExtractor struct{}
+
+func (tenantExtractor) Extract(ctx context.Context) Fields {
+	if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+		return Fields{"tenant": tenant}
+	}
+	return nil
+}
+
+func TestRegisterExtractor_MergesAllExtractors(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+	defer updateOptions(func(o *options) { o.extractors = nil })
+
+	RegisterExtractor(tenantExtractor{})
+	RegisterExtractor(ExtractorFunc(func(ctx context.Context) Fields {
+		return Fields{"trace_id": "trace-77"}
+	}))
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	Info(ctx, "extracted", nil)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	if entry["tenant"] != "acme" {
+		t.Errorf("expected tenant from the first extractor, got %v", entry["tenant"])
+	}
+	if entry["trace_id"] != "trace-77" {
+		t.Errorf("expected trace_id from the second extractor, got %v", entry["trace_id"])
+	}
+}
+
+type dumpKey string
+
+func TestDumpContext_ReportsPresentAndAbsentKeys(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.DebugLevel)
+
+	ctx := context.WithValue(context.Background(), dumpKey("tenant"), "acme")
+	ctx = context.WithValue(ctx, dumpKey("attempt"), 3)
+
+	DumpContext(ctx, dumpKey("tenant"), dumpKey("attempt"), dumpKey("user"))
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	if entry["level"] != "debug" {
+		t.Errorf("expected debug line, got %v", entry["level"])
+	}
+	values, ok := entry["context"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected context field to be an object, got %v", entry["context"])
+	}
+	if values["tenant"] != "acme" || values["attempt"] != float64(3) {
+		t.Errorf("expected tenant and attempt dumped, got %v", values)
+	}
+	if values["user"] != "<absent>" {
+		t.Errorf("expected missing key reported as absent, got %v", values["user"])
+	}
+}
+
+// dbPackage and apiPackage stand in for two packages: their methods are named
+// "<module>.dbPackage.query" and "<module>.apiPackage.handle".
+type dbPackage struct{}
+
+func (dbPackage) query(ctx context.Context) { Debug(ctx, "db debug", nil) }
+
+func (dbPackage) trace(ctx context.Context) { defer TraceFunc(ctx, "op")() }
+
+func (dbPackage) slogDebug(ctx context.Context) {
+	slog.New(NewSlogHandler()).DebugContext(ctx, "slog debug")
+}
+
+type apiPackage struct{}
+
+func (apiPackage) handle(ctx context.Context) { Debug(ctx, "api debug", nil) }
+
+func TestSetPackageLevel_OnlyMatchingPackage(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	SetPackageLevel("github.com/nandhasuhendra/logrus-wrapper.dbPackage", logrus.DebugLevel)
+	defer updateOptions(func(o *options) { o.packageLevels = nil })
+
+	ctx := context.Background()
+	dbPackage{}.query(ctx)
+	apiPackage{}.handle(ctx)
+
+	out := buf.String()
+	if !strings.Contains(out, "db debug") {
+		t.Errorf("expected Debug line from the overridden package, got %s", out)
+	}
+	if strings.Contains(out, "api debug") {
+		t.Errorf("expected Debug line from other packages to be suppressed, got %s", out)
+	}
+}
+
+func TestSetPackageLevel_TraceFuncAndSlog(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	SetPackageLevel("github.com/nandhasuhendra/logrus-wrapper.dbPackage", logrus.DebugLevel)
+	defer ClearPackageLevel("github.com/nandhasuhendra/logrus-wrapper.dbPackage")
+
+	ctx := context.Background()
+	dbPackage{}.trace(ctx)
+	dbPackage{}.slogDebug(ctx)
+
+	out := buf.String()
+	if !strings.Contains(out, "entering op") || !strings.Contains(out, "exiting op") {
+		t.Errorf("expected TraceFunc lines from the overridden package, got %s", out)
+	}
+	if !strings.Contains(out, "slog debug") {
+		t.Errorf("expected slog Debug line from the overridden package, got %s", out)
+	}
+}
+
+func TestClearPackageLevel(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	SetPackageLevel("github.com/nandhasuhendra/logrus-wrapper.dbPackage", logrus.DebugLevel)
+	ClearPackageLevel("github.com/nandhasuhendra/logrus-wrapper.dbPackage")
+
+	dbPackage{}.query(context.Background())
+
+	if buf.Len() != 0 {
+		t.Errorf("expected the cleared override to no longer apply, got %s", buf.String())
+	}
+	if n := len(loadOptions().packageLevels); n != 0 {
+		t.Errorf("expected no overrides left, got %d", n)
+	}
+}
@@ -0,0 +1,23 @@
+package logruswrapper
+
+import (
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// deprecatedSeen holds the names deprecatedOnce has already warned about.
+var deprecatedSeen sync.Map
+
+// deprecatedOnce logs a Warn line the first time the deprecated function
+// name is called in the process, and nothing on later calls.
+func deprecatedOnce(name string) {
+	if _, seen := deprecatedSeen.LoadOrStore(name, struct{}{}); seen {
+		return
+	}
+	if currentLevel() < logrus.WarnLevel {
+		return
+	}
+
+	log.WithField("deprecated", name).Warn(name + " is deprecated")
+}
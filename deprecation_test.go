@@ -0,0 +1,34 @@
+package logruswrapper
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestDeprecatedOnce_WarnsOnlyOnce(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+	deprecatedSeen.Delete("Trace2")
+
+	ctx := context.Background()
+	Trace2(ctx, "first")()
+	Trace2(ctx, "second")()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected a single deprecation warning, got %d lines: %s", len(lines), buf.String())
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+	if entry["level"] != "warning" || entry["deprecated"] != "Trace2" {
+		t.Errorf("expected warning naming Trace2, got %v", entry)
+	}
+}
@@ -0,0 +1,116 @@
+package logruswrapper
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Entry carries fields that are attached to every line logged through it.
+// Its Log method writes at the level the Entry was created for (Info unless
+// obtained from a Begin* helper).
+type Entry struct {
+	ctx    context.Context
+	level  logrus.Level
+	fields Fields
+}
+
+// BeginDebug returns an Entry for a Debug line and true, or nil and false
+// without allocating when Debug is disabled:
+//
+//	if e, ok := BeginDebug(ctx); ok {
+//		e.WithField("state", dump()).Log("state dump")
+//	}
+func BeginDebug(ctx context.Context) (*Entry, bool) {
+	return begin(ctx, callerPC(1), logrus.DebugLevel)
+}
+
+func begin(ctx context.Context, pc uintptr, level logrus.Level) (*Entry, bool) {
+	if levelFor(ctx, pc) < level {
+		return nil, false
+	}
+
+	return &Entry{ctx: ctx, level: level}, true
+}
+
+// WithAttempt returns an Info Entry carrying the retry "attempt" number:
+//
+//	WithAttempt(i).WithField("op", "sync").Warn(ctx, "retrying")
+func WithAttempt(n int) *Entry {
+	return &Entry{ctx: context.Background(), level: logrus.InfoLevel, fields: Fields{"attempt": n}}
+}
+
+func (e *Entry) WithField(key string, value interface{}) *Entry {
+	return e.WithFields(Fields{key: value})
+}
+
+func (e *Entry) WithFields(fields Fields) *Entry {
+	merged := make(Fields, len(e.fields)+len(fields))
+	for k, v := range e.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+
+	return &Entry{ctx: e.ctx, level: e.level, fields: merged}
+}
+
+func (e *Entry) Log(msg string) {
+	if !enabled(e.ctx, e.level, msg) {
+		return
+	}
+	callerFields := getCaller(e.level)
+	generateLogger(e.ctx, &e.fields).WithFields(*callerFields).Log(e.level, msg)
+}
+
+func (e *Entry) Info(ctx context.Context, msg string) {
+	if !enabled(ctx, logrus.InfoLevel, msg) {
+		return
+	}
+	callerFields := getCaller(logrus.InfoLevel)
+	generateLogger(ctx, &e.fields).WithFields(*callerFields).Info(msg)
+}
+
+func (e *Entry) Error(ctx context.Context, msg string, err error) {
+	if !enabled(ctx, logrus.ErrorLevel, msg) {
+		return
+	}
+	callerFields := getCaller(logrus.ErrorLevel)
+	logError(generateLogger(ctx, mergeErrorFields(err, &e.fields)).WithFields(*callerFields), msg, err)
+}
+
+func (e *Entry) Debug(ctx context.Context, msg string) {
+	if !enabled(ctx, logrus.DebugLevel, msg) {
+		return
+	}
+	callerFields := getCaller(logrus.DebugLevel)
+	generateLogger(ctx, &e.fields).WithFields(*callerFields).Debug(msg)
+}
+
+func (e *Entry) Warn(ctx context.Context, msg string) {
+	if !enabled(ctx, logrus.WarnLevel, msg) {
+		return
+	}
+	callerFields := getCaller(logrus.WarnLevel)
+	generateLogger(ctx, &e.fields).WithFields(*callerFields).Warn(msg)
+}
+
+type contextEntryKey struct{}
+
+// ContextWithEntry stores e on ctx so code further down the call stack can
+// log with its fields through FromContext.
+func ContextWithEntry(ctx context.Context, e *Entry) context.Context {
+	return context.WithValue(ctx, contextEntryKey{}, e)
+}
+
+// FromContext returns the Entry stored on ctx, bound to ctx, or an empty
+// Info Entry when there is none.
+func FromContext(ctx context.Context) *Entry {
+	e, _ := ctx.Value(contextEntryKey{}).(*Entry)
+	if e == nil {
+		return &Entry{ctx: ctx, level: logrus.InfoLevel}
+	}
+
+	return &Entry{ctx: ctx, level: e.level, fields: e.fields}
+}
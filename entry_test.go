@@ -0,0 +1,151 @@
+package logruswrapper
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestBeginDebug_Disabled(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	e, ok := BeginDebug(context.Background())
+	if ok || e != nil {
+		t.Fatalf("expected (nil, false) when Debug is disabled, got (%v, %v)", e, ok)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected no output, got: %s", buf.String())
+	}
+}
+
+func TestBeginDebug_Enabled(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.DebugLevel)
+	defer setLevel(logrus.InfoLevel)
+
+	e, ok := BeginDebug(context.Background())
+	if !ok {
+		t.Fatal("expected ok when Debug is enabled")
+	}
+	e.WithField("cache_size", 12).WithFields(Fields{"shard": "a"}).Log("cache state")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	if entry["level"] != "debug" {
+		t.Errorf("expected level 'debug', got %v", entry["level"])
+	}
+	if entry["msg"] != "cache state" {
+		t.Errorf("expected msg 'cache state', got %v", entry["msg"])
+	}
+	if entry["cache_size"] != float64(12) || entry["shard"] != "a" {
+		t.Errorf("expected cache_size and shard fields, got %v", entry)
+	}
+	if _, ok := entry["file"]; !ok {
+		t.Error("expected 'file' caller field in output")
+	}
+}
+
+func BenchmarkBeginDebug_Disabled(b *testing.B) {
+	log.SetOutput(io.Discard)
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if e, ok := BeginDebug(ctx); ok {
+			e.WithField("iteration", i).Log("never written")
+		}
+	}
+}
+
+func logDeep(ctx context.Context, depth int) {
+	if depth > 0 {
+		logDeep(ctx, depth-1)
+		return
+	}
+	FromContext(ctx).Info(ctx, "deep in the stack")
+}
+
+func TestFromContext_ReturnsStoredEntry(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	ctx := ContextWithEntry(context.Background(), FromContext(context.Background()).WithFields(Fields{
+		"request_id": "req-9",
+		"route":      "/orders",
+	}))
+	logDeep(ctx, 3)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	if entry["request_id"] != "req-9" || entry["route"] != "/orders" {
+		t.Errorf("expected fields from the context entry, got %v", entry)
+	}
+	if fn, _ := entry["func"].(string); !strings.HasSuffix(fn, ".logDeep") {
+		t.Errorf("expected caller to be logDeep, got %v", entry["func"])
+	}
+}
+
+func TestFromContext_DefaultEntry(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	e := FromContext(context.Background())
+	if e == nil {
+		t.Fatal("expected a default entry when none is stored")
+	}
+	e.Log("default entry")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+	if entry["level"] != "info" || entry["msg"] != "default entry" {
+		t.Errorf("expected an info line from the default entry, got %v", entry)
+	}
+}
+
+func TestWithAttempt_IncrementsPerRetry(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	ctx := context.Background()
+	for i := 1; i <= 3; i++ {
+		WithAttempt(i).WithField("op", "sync").Warn(ctx, "retrying")
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d: %s", len(lines), buf.String())
+	}
+	for i, line := range lines {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("expected valid JSON output: %v", err)
+		}
+		if entry["attempt"] != float64(i+1) {
+			t.Errorf("expected attempt %d, got %v", i+1, entry["attempt"])
+		}
+		if entry["op"] != "sync" || entry["level"] != "warning" {
+			t.Errorf("expected op field on a warning line, got %v", entry)
+		}
+	}
+}
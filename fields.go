@@ -0,0 +1,433 @@
+package logruswrapper
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+)
+
+// lazyValue defers a func() interface{} field until the entry is written;
+// logrus itself rejects func-typed field values.
+type lazyValue struct {
+	fn func() interface{}
+}
+
+// SetNestCustomFields moves every user-supplied field (from the call, the
+// context and extractors) under a single key, leaving msg, level, time, the
+// caller and error fields at the top level. An empty key restores top-level
+// fields.
+func SetNestCustomFields(key string) {
+	updateOptions(func(o *options) { o.nestFieldsKey = key })
+}
+
+// UserID returns the "user_id" field.
+func UserID(v string) Fields {
+	return Fields{"user_id": v}
+}
+
+// RequestID returns the "request_id" field.
+func RequestID(v string) Fields {
+	return Fields{requestIDKey: v}
+}
+
+// Merge combines fields into a new map; later keys win on collision.
+func Merge(fields ...Fields) Fields {
+	n := 0
+	for _, f := range fields {
+		n += len(f)
+	}
+
+	merged := make(Fields, n)
+	for _, f := range fields {
+		for k, v := range f {
+			merged[k] = v
+		}
+	}
+
+	return merged
+}
+
+// Ptr returns a pointer to f for the *Fields parameters of the log
+// functions. Fields is an alias of logrus.Fields, so this cannot be a
+// method: use Ptr(Merge(UserID(u), RequestID(r))).
+func Ptr(f Fields) *Fields {
+	return &f
+}
+
+// levelScopedValue is a field value that is only written at level or a more
+// verbose one; see OnlyAtLevel.
+type levelScopedValue struct {
+	level logrus.Level
+	value interface{}
+}
+
+// OnlyAtLevel wraps a field value so it is only included when the entry is
+// logged at level or more verbose, e.g. a large payload that should appear on
+// Debug lines but be dropped from the same call at Info.
+func OnlyAtLevel(level logrus.Level, value interface{}) interface{} {
+	if fn, ok := value.(func() interface{}); ok {
+		value = lazyValue{fn: fn}
+	}
+
+	return levelScopedValue{level: level, value: value}
+}
+
+// prepareFields returns fields ready to be attached to an entry. The caller's
+// map is only copied when a key or value has to be rewritten.
+func prepareFields(fields Fields) Fields {
+	o := loadOptions()
+	if o.keyCase != KeyCaseNone {
+		fields = normalizeKeys(fields, o.keyCase)
+	}
+
+	out := fields
+	copied := false
+	ensureCopy := func() {
+		if copied {
+			return
+		}
+		out = make(Fields, len(fields))
+		for k, v := range fields {
+			out[k] = v
+		}
+		copied = true
+	}
+
+	for k, v := range fields {
+		key, value, changed := k, v, false
+
+		if fn, ok := o.typeFormatters[reflect.TypeOf(v)]; ok {
+			v = fn(v)
+			value, changed = v, true
+		}
+
+		if b, ok := v.([]byte); ok && o.byteSliceEncoding != ByteSliceDefault {
+			value, changed = encodeByteSlice(b, o.byteSliceEncoding), true
+		} else if fn, ok := v.(func() interface{}); ok {
+			value, changed = lazyValue{fn: fn}, true
+		} else if !jsonSafe(v) {
+			value, changed = fmt.Sprintf("%v", v), true
+			ensureCopy()
+			out["_unserializable_"+key] = true
+		}
+
+		if changed {
+			ensureCopy()
+			out[key] = value
+		}
+	}
+
+	return out
+}
+
+// SetMaxFieldValueLength truncates string and error field values longer than
+// n bytes
+// and marks them with "<key>_truncated": true. n <= 0 disables the cap.
+func SetMaxFieldValueLength(n int) {
+	updateOptions(func(o *options) { o.maxFieldValueLength = n })
+}
+
+// valueRedaction masks every match of re with replacement.
+type valueRedaction struct {
+	re          *regexp.Regexp
+	replacement string
+}
+
+// AddValueRedactionPattern masks every match of re in string and error field
+// values, including the error passed to Error, and in the message with
+// replacement, whatever the field is called.
+func AddValueRedactionPattern(re *regexp.Regexp, replacement string) {
+	updateOptions(func(o *options) {
+		o.valueRedactions = append(append([]valueRedaction(nil), o.valueRedactions...), valueRedaction{re: re, replacement: replacement})
+	})
+}
+
+func redactValue(s string, redactions []valueRedaction) string {
+	for _, r := range redactions {
+		s = r.re.ReplaceAllString(s, r.replacement)
+	}
+
+	return s
+}
+
+// truncateString cuts s to at most n bytes without splitting a UTF-8
+// sequence.
+func truncateString(s string, n int) string {
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+
+	return s[:n]
+}
+
+// jsonSafe reports whether v can be encoded by the JSON formatter.
+func jsonSafe(v interface{}) (ok bool) {
+	switch v := v.(type) {
+	case nil, string, bool, int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64, time.Time, time.Duration, error:
+		return true
+	case float64:
+		return !math.IsNaN(v) && !math.IsInf(v, 0)
+	case float32:
+		return !math.IsNaN(float64(v)) && !math.IsInf(float64(v), 0)
+	}
+
+	if alwaysEncodes(reflect.TypeOf(v)) {
+		return true
+	}
+
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	_, err := json.Marshal(v)
+
+	return err == nil
+}
+
+var (
+	// encodableTypes caches alwaysEncodes per reflect.Type so most field
+	// values skip the json.Marshal probe in jsonSafe.
+	encodableTypes sync.Map
+
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// alwaysEncodes reports whether every value of t encodes to JSON. It is
+// false for types whose encoding depends on the value — floats, interfaces,
+// custom marshalers and recursive types — which jsonSafe still probes.
+func alwaysEncodes(t reflect.Type) bool {
+	if ok, found := encodableTypes.Load(t); found {
+		return ok.(bool)
+	}
+	ok := typeEncodes(t, map[reflect.Type]bool{})
+	encodableTypes.Store(t, ok)
+
+	return ok
+}
+
+func typeEncodes(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	// A type that contains itself can hold a cycle.
+	if visiting[t] {
+		return false
+	}
+	if t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) {
+		return false
+	}
+	if t.Kind() != reflect.Pointer {
+		pt := reflect.PointerTo(t)
+		if pt.Implements(jsonMarshalerType) || pt.Implements(textMarshalerType) {
+			return false
+		}
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return typeEncodes(t.Elem(), visiting)
+	case reflect.Map:
+		switch t.Key().Kind() {
+		case reflect.String,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		default:
+			return false
+		}
+		return !t.Key().Implements(textMarshalerType) && typeEncodes(t.Elem(), visiting)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() && !f.Anonymous {
+				continue
+			}
+			if !typeEncodes(f.Type, visiting) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+// RegisterTypeFormatter renders every field value of sample's dynamic type
+// through fn before it is formatted, e.g. to write a UUID type as its
+// string form. Registering a type again replaces its formatter.
+func RegisterTypeFormatter(sample interface{}, fn func(interface{}) interface{}) {
+	t := reflect.TypeOf(sample)
+	updateOptions(func(o *options) {
+		formatters := make(map[reflect.Type]func(interface{}) interface{}, len(o.typeFormatters)+1)
+		for k, v := range o.typeFormatters {
+			formatters[k] = v
+		}
+		formatters[t] = fn
+		o.typeFormatters = formatters
+	})
+}
+
+// ByteSliceEncoding selects how []byte field values are written.
+type ByteSliceEncoding int
+
+const (
+	// ByteSliceDefault leaves []byte values to the formatter.
+	ByteSliceDefault ByteSliceEncoding = iota
+	ByteSliceBase64
+	ByteSliceHex
+	// ByteSliceString writes the bytes as a string as-is.
+	ByteSliceString
+)
+
+// SetByteSliceEncoding writes every []byte field value as a string in the
+// given encoding, so the output does not depend on the formatter.
+func SetByteSliceEncoding(mode ByteSliceEncoding) {
+	updateOptions(func(o *options) { o.byteSliceEncoding = mode })
+}
+
+func encodeByteSlice(b []byte, mode ByteSliceEncoding) string {
+	switch mode {
+	case ByteSliceHex:
+		return hex.EncodeToString(b)
+	case ByteSliceString:
+		return string(b)
+	}
+
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// KeyCase selects how field keys are normalized.
+type KeyCase int
+
+const (
+	KeyCaseNone KeyCase = iota
+	KeyCaseSnake
+	KeyCaseCamel
+)
+
+// SetFieldKeyCase normalizes every field key to the given case, so userId,
+// UserID and user_id all end up as the same key. The msg, level and time
+// keys are never rewritten. When several keys in one call collide, the one
+// already in the target case wins and the others are listed under
+// "_key_collision_<key>".
+func SetFieldKeyCase(mode KeyCase) {
+	updateOptions(func(o *options) { o.keyCase = mode })
+}
+
+// normalizeKeys returns fields with every key normalized to mode, or fields
+// itself when no key changes. When several keys normalize to the same name,
+// the one already spelled that way wins, else the smallest original key; the
+// dropped original keys are listed under "_key_collision_<key>".
+func normalizeKeys(fields Fields, mode KeyCase) Fields {
+	changed := false
+	for k := range fields {
+		if normalizeKey(k, mode) != k {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return fields
+	}
+
+	out := make(Fields, len(fields))
+	owners := make(map[string]string, len(fields))
+	var collisions map[string][]string
+	for k, v := range fields {
+		key := normalizeKey(k, mode)
+		if owner, ok := owners[key]; ok {
+			if collisions == nil {
+				collisions = map[string][]string{}
+			}
+			if owner == key || (k != key && owner < k) {
+				collisions[key] = append(collisions[key], k)
+				continue
+			}
+			collisions[key] = append(collisions[key], owner)
+		}
+		owners[key] = k
+		out[key] = v
+	}
+	for key, dropped := range collisions {
+		sort.Strings(dropped)
+		out["_key_collision_"+key] = dropped
+	}
+
+	return out
+}
+
+func normalizeKey(key string, mode KeyCase) string {
+	switch key {
+	case logrus.FieldKeyMsg, logrus.FieldKeyLevel, logrus.FieldKeyTime:
+		return key
+	}
+
+	segments := strings.Split(key, ".")
+	for i, segment := range segments {
+		words := splitKeyWords(segment)
+		for j, w := range words {
+			w = strings.ToLower(w)
+			if mode == KeyCaseCamel && j > 0 {
+				r, size := utf8.DecodeRuneInString(w)
+				w = string(unicode.ToUpper(r)) + w[size:]
+			}
+			words[j] = w
+		}
+		if mode == KeyCaseCamel {
+			segments[i] = strings.Join(words, "")
+		} else {
+			segments[i] = strings.Join(words, "_")
+		}
+	}
+
+	return strings.Join(segments, ".")
+}
+
+// splitKeyWords splits s on separators and case changes, keeping acronyms
+// together: "HTTPStatusCode" -> [HTTP Status Code], "user_id" -> [user id].
+func splitKeyWords(s string) []string {
+	var words []string
+	runes := []rune(s)
+	start := 0
+	flush := func(end int) {
+		if end > start {
+			words = append(words, string(runes[start:end]))
+		}
+	}
+
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || r == ' ':
+			flush(i)
+			start = i + 1
+		case i > start && unicode.IsUpper(r):
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if !unicode.IsUpper(prev) || nextLower {
+				flush(i)
+				start = i
+			}
+		}
+	}
+	flush(len(runes))
+
+	return words
+}
@@ -0,0 +1,402 @@
+package logruswrapper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+type panickyMarshaler struct{}
+
+func (panickyMarshaler) MarshalJSON() ([]byte, error) {
+	panic("cannot marshal")
+}
+
+func TestGenerateLogger_SanitizesUnserializableValues(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	ch := make(chan int)
+	fields := Fields{"events": ch, "payload": panickyMarshaler{}, "ok": "fine"}
+	Info(context.Background(), "sanitized", &fields)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output, got %v: %s", err, buf.String())
+	}
+
+	if entry["msg"] != "sanitized" {
+		t.Errorf("expected msg 'sanitized', got %v", entry["msg"])
+	}
+	if _, ok := entry["events"].(string); !ok {
+		t.Errorf("expected channel to be rendered as a string, got %v", entry["events"])
+	}
+	if entry["_unserializable_events"] != true {
+		t.Error("expected _unserializable_events marker")
+	}
+	if entry["_unserializable_payload"] != true {
+		t.Error("expected _unserializable_payload marker")
+	}
+	if _, ok := entry["_unserializable_ok"]; ok {
+		t.Error("expected no marker for a serializable value")
+	}
+	if _, ok := fields["_unserializable_events"]; ok {
+		t.Error("expected caller's fields map to be left untouched")
+	}
+}
+
+func TestJSONSafe_CachesTypesThatAlwaysEncode(t *testing.T) {
+	type plain struct {
+		ID   int
+		Tags []string
+	}
+	type measured struct {
+		Value float64
+	}
+	type node struct {
+		Next *node
+	}
+
+	if !jsonSafe(plain{ID: 1}) || !alwaysEncodes(reflect.TypeOf(plain{})) {
+		t.Error("expected a struct of ints and strings to be cached as always safe")
+	}
+	if alwaysEncodes(reflect.TypeOf(measured{})) {
+		t.Error("expected a float field to need a per-value check")
+	}
+	if jsonSafe(measured{Value: math.NaN()}) || !jsonSafe(measured{Value: 1.5}) {
+		t.Error("expected float structs to be checked by value")
+	}
+	cyclic := &node{}
+	cyclic.Next = cyclic
+	if jsonSafe(cyclic) {
+		t.Error("expected a cyclic value to stay unsafe")
+	}
+}
+
+func TestSetFieldKeyCase(t *testing.T) {
+	tests := []struct {
+		mode KeyCase
+		want []string
+	}{
+		{KeyCaseSnake, []string{"user_id", "http_status_code", "order_ref"}},
+		{KeyCaseCamel, []string{"userId", "httpStatusCode", "orderRef"}},
+	}
+
+	for _, tt := range tests {
+		buf := captureOutput()
+		setLevel(logrus.InfoLevel)
+		SetFieldKeyCase(tt.mode)
+
+		fields := Fields{"UserID": "u-1", "HTTPStatusCode": 200, "order-ref": "o-9"}
+		Info(context.Background(), "mixed keys", &fields)
+		SetFieldKeyCase(KeyCaseNone)
+		restoreOutput()
+
+		var entry map[string]interface{}
+		if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+			t.Fatalf("expected valid JSON output: %v", err)
+		}
+
+		for _, key := range tt.want {
+			if _, ok := entry[key]; !ok {
+				t.Errorf("mode %d: expected normalized key %q, got %v", tt.mode, key, entry)
+			}
+		}
+		for key := range fields {
+			if _, ok := entry[key]; ok {
+				t.Errorf("mode %d: expected original key %q to be replaced", tt.mode, key)
+			}
+		}
+		if entry["msg"] != "mixed keys" || entry["level"] != "info" {
+			t.Errorf("mode %d: expected reserved keys to be untouched, got %v", tt.mode, entry)
+		}
+	}
+}
+
+func TestNormalizeKey_CamelKeepsMultibyteRunes(t *testing.T) {
+	if got := normalizeKey("user_émail", KeyCaseCamel); got != "userÉmail" {
+		t.Errorf("expected userÉmail, got %q", got)
+	}
+}
+
+func TestNormalizeKeys_CollisionsAreDeterministic(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		out := normalizeKeys(Fields{"userId": 1, "user_id": 2, "UserID": 3}, KeyCaseSnake)
+
+		if out["user_id"] != 2 {
+			t.Fatalf("expected the already-snake key to win, got %v", out)
+		}
+		dropped, _ := out["_key_collision_user_id"].([]string)
+		if len(dropped) != 2 || dropped[0] != "UserID" || dropped[1] != "userId" {
+			t.Fatalf("expected dropped keys [UserID userId], got %v", out["_key_collision_user_id"])
+		}
+	}
+
+	out := normalizeKeys(Fields{"userId": 1, "UserID": 3}, KeyCaseSnake)
+	if out["user_id"] != 3 {
+		t.Errorf("expected the smallest original key to win, got %v", out)
+	}
+}
+
+func TestNormalizeKey_ReservedKeysExempt(t *testing.T) {
+	for _, key := range []string{"msg", "level", "time"} {
+		if got := normalizeKey(key, KeyCaseCamel); got != key {
+			t.Errorf("expected reserved key %q to be unchanged, got %q", key, got)
+		}
+	}
+}
+
+func TestSetMaxFieldValueLength(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	SetMaxFieldValueLength(8)
+	defer SetMaxFieldValueLength(0)
+
+	fields := Fields{"blob": strings.Repeat("a", 100), "short": "tiny"}
+	Info(context.Background(), "capped", &fields)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	if entry["blob"] != "aaaaaaaa" {
+		t.Errorf("expected blob truncated to 8 bytes, got %v", entry["blob"])
+	}
+	if entry["blob_truncated"] != true {
+		t.Error("expected blob_truncated marker")
+	}
+	if entry["short"] != "tiny" {
+		t.Errorf("expected short value untouched, got %v", entry["short"])
+	}
+	if _, ok := entry["short_truncated"]; ok {
+		t.Error("expected no marker for a value under the cap")
+	}
+	if len(fields["blob"].(string)) != 100 {
+		t.Error("expected caller's fields map to be left untouched")
+	}
+}
+
+func TestOnlyAtLevel(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.DebugLevel)
+
+	ctx := context.Background()
+	fields := Fields{"payload": OnlyAtLevel(logrus.DebugLevel, "verbose"), "id": 7}
+
+	Info(ctx, "info line", &fields)
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+	if _, ok := entry["payload"]; ok {
+		t.Errorf("expected debug-only field omitted at Info, got %v", entry["payload"])
+	}
+	if entry["id"] != float64(7) {
+		t.Errorf("expected regular field kept, got %v", entry["id"])
+	}
+
+	buf.Reset()
+	Debug(ctx, "debug line", &fields)
+	entry = nil
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+	if entry["payload"] != "verbose" {
+		t.Errorf("expected debug-only field included at Debug, got %v", entry["payload"])
+	}
+}
+
+func TestAddValueRedactionPattern(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	AddValueRedactionPattern(regexp.MustCompile(`\b\d{4}[- ]?\d{4}[- ]?\d{4}[- ]?\d{4}\b`), "[card]")
+	defer updateOptions(func(o *options) { o.valueRedactions = nil })
+
+	fields := Fields{"note": "paid with 4111-1111-1111-1111 today", "amount": 42}
+	Info(context.Background(), "charge 4111 1111 1111 1111 failed", &fields)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	if entry["note"] != "paid with [card] today" {
+		t.Errorf("expected card masked in field value, got %v", entry["note"])
+	}
+	if entry["msg"] != "charge [card] failed" {
+		t.Errorf("expected card masked in message, got %v", entry["msg"])
+	}
+	if entry["amount"] != float64(42) {
+		t.Errorf("expected non-string value untouched, got %v", entry["amount"])
+	}
+	if !strings.Contains(fields["note"].(string), "4111") {
+		t.Error("expected caller's fields map to be left untouched")
+	}
+}
+
+func TestAddValueRedactionPattern_ResolvedAndErrorValues(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	AddValueRedactionPattern(regexp.MustCompile(`\b\d{4}[- ]?\d{4}[- ]?\d{4}[- ]?\d{4}\b`), "[card]")
+	defer updateOptions(func(o *options) { o.valueRedactions = nil })
+
+	card := "4111-1111-1111-1111"
+	fields := Fields{
+		"lazy":   func() interface{} { return "lazy " + card },
+		"scoped": OnlyAtLevel(logrus.ErrorLevel, "scoped "+card),
+		"cause":  errors.New("field " + card),
+	}
+	Error(context.Background(), "charge failed", errors.New("declined "+card), &fields)
+
+	if strings.Contains(buf.String(), card) {
+		t.Fatalf("expected every card number masked, got %s", buf.String())
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+	if entry["error"] != "declined [card]" || entry["cause"] != "field [card]" {
+		t.Errorf("expected error values masked, got %v", entry)
+	}
+	if entry["lazy"] != "lazy [card]" || entry["scoped"] != "scoped [card]" {
+		t.Errorf("expected resolved values masked, got %v", entry)
+	}
+}
+
+func TestMerge_LaterKeysWin(t *testing.T) {
+	first := Fields{"user_id": "old", "a": 1}
+	merged := Merge(first, UserID("u-1"), RequestID("r-1"), nil)
+
+	if merged["user_id"] != "u-1" || merged["request_id"] != "r-1" || merged["a"] != 1 {
+		t.Errorf("expected merged fields with later keys winning, got %v", merged)
+	}
+	if first["user_id"] != "old" {
+		t.Error("expected inputs to be left untouched")
+	}
+}
+
+func TestPtr_UsableWithLogFunctions(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	Info(context.Background(), "constructed", Ptr(Merge(UserID("u-1"), RequestID("r-1"))))
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+	if entry["user_id"] != "u-1" || entry["request_id"] != "r-1" {
+		t.Errorf("expected user_id and request_id fields, got %v", entry)
+	}
+}
+
+func TestSetNestCustomFields(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	SetNestCustomFields("fields")
+	defer SetNestCustomFields("")
+
+	ctx := ContextWithFields(context.Background(), Fields{"tenant": "acme"})
+	fields := Fields{"level": "custom", "lazy": func() interface{} { return "resolved" }}
+	Info(ctx, "nested", &fields)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	nested, ok := entry["fields"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected user fields nested under 'fields', got %v", entry)
+	}
+	if nested["tenant"] != "acme" || nested["level"] != "custom" || nested["lazy"] != "resolved" {
+		t.Errorf("expected context, call and lazy fields nested, got %v", nested)
+	}
+	if entry["level"] != "info" || entry["msg"] != "nested" || entry["time"] == nil {
+		t.Errorf("expected reserved keys at the top level, got %v", entry)
+	}
+	if entry["file"] == nil || entry["func"] == nil {
+		t.Errorf("expected caller fields at the top level, got %v", entry)
+	}
+	if _, ok := entry["tenant"]; ok {
+		t.Error("expected no user fields at the top level")
+	}
+}
+
+func TestSetByteSliceEncoding(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+	defer SetByteSliceEncoding(ByteSliceDefault)
+
+	tests := []struct {
+		mode ByteSliceEncoding
+		want string
+	}{
+		{ByteSliceBase64, "aGk="},
+		{ByteSliceHex, "6869"},
+		{ByteSliceString, "hi"},
+	}
+
+	for _, tt := range tests {
+		buf.Reset()
+		SetByteSliceEncoding(tt.mode)
+		Info(context.Background(), "bytes", &Fields{"payload": []byte("hi")})
+
+		var entry map[string]interface{}
+		if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+			t.Fatalf("expected valid JSON output: %v", err)
+		}
+		if entry["payload"] != tt.want {
+			t.Errorf("mode %d: expected payload %q, got %v", tt.mode, tt.want, entry["payload"])
+		}
+	}
+}
+
+type accountID [4]byte
+
+func TestRegisterTypeFormatter(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	RegisterTypeFormatter(accountID{}, func(v interface{}) interface{} {
+		id := v.(accountID)
+		return fmt.Sprintf("acct-%x", id[:])
+	})
+	defer updateOptions(func(o *options) { o.typeFormatters = nil })
+
+	Info(context.Background(), "formatted", &Fields{"account": accountID{0xde, 0xad, 0xbe, 0xef}, "other": "plain"})
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+	if entry["account"] != "acct-deadbeef" {
+		t.Errorf("expected formatted account, got %v", entry["account"])
+	}
+	if entry["other"] != "plain" {
+		t.Errorf("expected other values untouched, got %v", entry["other"])
+	}
+}
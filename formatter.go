@@ -0,0 +1,513 @@
+package logruswrapper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var compactLevels = map[logrus.Level]string{
+	logrus.PanicLevel: "P",
+	logrus.FatalLevel: "F",
+	logrus.ErrorLevel: "E",
+	logrus.WarnLevel:  "W",
+	logrus.InfoLevel:  "I",
+	logrus.DebugLevel: "D",
+	logrus.TraceLevel: "T",
+}
+
+var compactPrevFormatter logrus.Formatter
+
+var compactEscaper = strings.NewReplacer(`\`, `\\`, "|", `\|`, ",", `\,`, "=", `\=`, "\n", `\n`)
+
+// compactFormatter renders entries as "L|msg|k=v,k=v" lines with a one-letter
+// level and keys sorted alphabetically.
+type compactFormatter struct{}
+
+func (compactFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	b := &bytes.Buffer{}
+	b.WriteString(compactLevels[entry.Level])
+	b.WriteByte('|')
+	b.WriteString(compactEscaper.Replace(entry.Message))
+	b.WriteByte('|')
+
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		v := entry.Data[k]
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		b.WriteString(compactEscaper.Replace(k))
+		b.WriteByte('=')
+		b.WriteString(compactEscaper.Replace(fmt.Sprint(v)))
+	}
+	b.WriteByte('\n')
+
+	return b.Bytes(), nil
+}
+
+// SetCompactFormat switches to the compact "L|msg|k=v,k=v" line format meant
+// for bandwidth-constrained devices. Disabling it restores the previous formatter.
+func SetCompactFormat(enabled bool) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	_, active := log.Formatter.(compactFormatter)
+	switch {
+	case enabled && !active:
+		compactPrevFormatter = log.Formatter
+		log.SetFormatter(compactFormatter{})
+	case !enabled && active:
+		applyFormatter(compactPrevFormatter)
+		compactPrevFormatter = nil
+	}
+}
+
+// ParseCompactLine parses a line produced by the compact format back into its
+// level letter, message and fields.
+func ParseCompactLine(line string) (level, msg string, fields map[string]string, err error) {
+	parts := splitEscaped(strings.TrimRight(line, "\n"), '|')
+	if len(parts) != 3 {
+		return "", "", nil, fmt.Errorf("compact line: expected 3 sections, got %d", len(parts))
+	}
+
+	fields = map[string]string{}
+	if parts[2] != "" {
+		for _, pair := range splitEscaped(parts[2], ',') {
+			kv := splitEscaped(pair, '=')
+			if len(kv) != 2 {
+				return "", "", nil, fmt.Errorf("compact line: malformed field %q", pair)
+			}
+			fields[unescapeCompact(kv[0])] = unescapeCompact(kv[1])
+		}
+	}
+
+	return parts[0], unescapeCompact(parts[1]), fields, nil
+}
+
+// splitEscaped splits s on sep, ignoring separators preceded by a backslash.
+// The returned parts are still escaped.
+func splitEscaped(s string, sep byte) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case sep:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, s[start:])
+}
+
+func unescapeCompact(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	b := strings.Builder{}
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			if s[i] == 'n' {
+				b.WriteByte('\n')
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+
+	return b.String()
+}
+
+// fallbackFormatter falls back to a best-effort plain-text line when the
+// wrapped formatter fails, so the message is not lost.
+type fallbackFormatter struct {
+	logrus.Formatter
+}
+
+func (f fallbackFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	b, err := f.Formatter.Format(entry)
+	if err == nil {
+		return b, nil
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "time=%q level=%s msg=%q", entry.Time.Format(time.RFC3339), entry.Level, entry.Message)
+	if v, ok := entry.Data[logrus.ErrorKey]; ok {
+		fmt.Fprintf(buf, " error=%q", fmt.Sprint(v))
+	}
+	fmt.Fprintf(buf, " format_error=%q\n", err.Error())
+
+	return buf.Bytes(), nil
+}
+
+// SetFormatterFallback wraps the active formatter so entries it fails to
+// format are still written as plain text.
+func SetFormatterFallback(enabled bool) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	current, active := log.Formatter.(fallbackFormatter)
+	switch {
+	case enabled && !active:
+		log.SetFormatter(fallbackFormatter{log.Formatter})
+	case !enabled && active:
+		log.SetFormatter(current.Formatter)
+	}
+}
+
+// loggedBytes is the total size of the lines formatted while
+// SetIncludeEntrySize is enabled.
+var loggedBytes atomic.Uint64
+
+// sizeFormatter adds the length of every line the wrapped formatter
+// produces to loggedBytes.
+type sizeFormatter struct {
+	logrus.Formatter
+}
+
+func (f sizeFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	b, err := f.Formatter.Format(entry)
+	if err == nil {
+		loggedBytes.Add(uint64(len(b)))
+	}
+
+	return b, err
+}
+
+// SetIncludeEntrySize wraps the active formatter so the serialized size of
+// every line is added to the running total reported by LoggedBytes. A line
+// cannot carry its own final size, so it is reported out of band.
+func SetIncludeEntrySize(enabled bool) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	current, active := log.Formatter.(sizeFormatter)
+	switch {
+	case enabled && !active:
+		log.SetFormatter(sizeFormatter{log.Formatter})
+	case !enabled && active:
+		log.SetFormatter(current.Formatter)
+	}
+}
+
+// LoggedBytes returns the total serialized size of the lines written while
+// SetIncludeEntrySize was enabled.
+func LoggedBytes() uint64 {
+	return loggedBytes.Load()
+}
+
+// orderedJSONFormatter writes JSON with the keys in order first and the
+// remaining keys sorted alphabetically.
+type orderedJSONFormatter struct {
+	order           []string
+	timestampFormat string
+}
+
+func (f orderedJSONFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	millis := f.timestampFormat == TimestampUnixMillis
+	data := make(Fields, len(entry.Data)+3)
+	for k, v := range entry.Data {
+		switch k {
+		case logrus.FieldKeyTime, logrus.FieldKeyLevel, logrus.FieldKeyMsg:
+			k = "fields." + k
+		}
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		data[k] = v
+	}
+	if millis {
+		data[logrus.FieldKeyTime] = entry.Time.UnixMilli()
+	} else {
+		data[logrus.FieldKeyTime] = entry.Time.Format(f.timestampFormat)
+	}
+	data[logrus.FieldKeyLevel] = entry.Level.String()
+	data[logrus.FieldKeyMsg] = entry.Message
+
+	keys := make([]string, 0, len(data))
+	seen := make(map[string]bool, len(f.order))
+	for _, k := range f.order {
+		if _, ok := data[k]; ok && !seen[k] {
+			keys = append(keys, k)
+			seen[k] = true
+		}
+	}
+	rest := make([]string, 0, len(data)-len(keys))
+	for k := range data {
+		if !seen[k] {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+	keys = append(keys, rest...)
+
+	b := &bytes.Buffer{}
+	b.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(k)
+		value, err := json.Marshal(data[k])
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal field %q to JSON, %w", k, err)
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteString("}\n")
+
+	return b.Bytes(), nil
+}
+
+var fieldOrderPrevFormatter logrus.Formatter
+
+// SetFieldOrder switches to JSON output where keys are emitted first in
+// the given order, followed by the remaining keys alphabetically. Calling it
+// with no keys restores the previous formatter.
+func SetFieldOrder(keys ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	_, active := log.Formatter.(orderedJSONFormatter)
+	if len(keys) == 0 {
+		if active {
+			applyFormatter(fieldOrderPrevFormatter)
+			fieldOrderPrevFormatter = nil
+		}
+		return
+	}
+
+	if !active {
+		fieldOrderPrevFormatter = log.Formatter
+	}
+	timestampFormat := time.RFC3339
+	if jf, ok := fieldOrderPrevFormatter.(*logrus.JSONFormatter); ok && jf.TimestampFormat != "" {
+		timestampFormat = jf.TimestampFormat
+	}
+	applyFormatter(orderedJSONFormatter{
+		order:           append([]string(nil), keys...),
+		timestampFormat: timestampFormat,
+	})
+}
+
+// TimestampUnixMillis can be passed to SetTimestampFormat to write the time
+// field as milliseconds since the Unix epoch instead of a formatted string.
+const TimestampUnixMillis = "unix_millis"
+
+// unusedTimeKey takes over the wrapped formatter's own time key while Unix
+// millis are enabled, so the numeric "time" field added by
+// unixMillisFormatter is not treated as a clash and renamed to "fields.time".
+const unusedTimeKey = "\x00time"
+
+// SetTimestampFormat sets the layout of the time field, or
+// TimestampUnixMillis for an epoch-millis number. It applies to the active
+// formatter and to every formatter installed afterwards, e.g. by Setup. An
+// empty layout restores the formatters' defaults.
+func SetTimestampFormat(layout string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	updateOptions(func(o *options) { o.timestampFormat = layout })
+	log.SetFormatter(withTimestampFormat(log.Formatter, layout))
+}
+
+// applyFormatter installs f with the layout set through SetTimestampFormat.
+// Callers must hold mu.
+func applyFormatter(f logrus.Formatter) {
+	if layout := loadOptions().timestampFormat; layout != "" {
+		f = withTimestampFormat(f, layout)
+	}
+	log.SetFormatter(f)
+}
+
+// unixMillisFormatter writes the time field of the wrapped logrus formatter
+// as milliseconds since the Unix epoch.
+type unixMillisFormatter struct {
+	logrus.Formatter
+}
+
+func (f unixMillisFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	data := make(Fields, len(entry.Data)+1)
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+	if t, ok := data[logrus.FieldKeyTime]; ok {
+		data["fields."+logrus.FieldKeyTime] = t
+	}
+	data[logrus.FieldKeyTime] = entry.Time.UnixMilli()
+
+	stamped := *entry
+	stamped.Data = data
+
+	return f.Formatter.Format(&stamped)
+}
+
+// withTimestampFormat returns a copy of f using layout. Formatters that write
+// their own timestamp key in a fixed format, such as ECS, are returned as is.
+func withTimestampFormat(f logrus.Formatter, layout string) logrus.Formatter {
+	switch f := f.(type) {
+	case *logrus.JSONFormatter:
+		c := *f
+		c.TimestampFormat, c.DisableTimestamp, c.FieldMap = timestampSettings(layout, f.FieldMap)
+		return withUnixMillis(&c, layout)
+	case *logrus.TextFormatter:
+		c := &logrus.TextFormatter{
+			ForceColors:               f.ForceColors,
+			DisableColors:             f.DisableColors,
+			ForceQuote:                f.ForceQuote,
+			DisableQuote:              f.DisableQuote,
+			EnvironmentOverrideColors: f.EnvironmentOverrideColors,
+			FullTimestamp:             f.FullTimestamp,
+			DisableSorting:            f.DisableSorting,
+			SortingFunc:               f.SortingFunc,
+			DisableLevelTruncation:    f.DisableLevelTruncation,
+			PadLevelText:              f.PadLevelText,
+			QuoteEmptyFields:          f.QuoteEmptyFields,
+			CallerPrettyfier:          f.CallerPrettyfier,
+		}
+		c.TimestampFormat, c.DisableTimestamp, c.FieldMap = timestampSettings(layout, f.FieldMap)
+		return withUnixMillis(c, layout)
+	case orderedJSONFormatter:
+		f.timestampFormat = layout
+		if layout == "" {
+			f.timestampFormat = time.RFC3339
+		}
+		return f
+	case unixMillisFormatter:
+		return withTimestampFormat(f.Formatter, layout)
+	case fallbackFormatter:
+		return fallbackFormatter{withTimestampFormat(f.Formatter, layout)}
+	case sizeFormatter:
+		return sizeFormatter{withTimestampFormat(f.Formatter, layout)}
+	}
+
+	return f
+}
+
+func withUnixMillis(f logrus.Formatter, layout string) logrus.Formatter {
+	if layout == TimestampUnixMillis {
+		return unixMillisFormatter{f}
+	}
+
+	return f
+}
+
+// timestampSettings returns the TimestampFormat, DisableTimestamp and
+// FieldMap a logrus formatter needs for layout.
+func timestampSettings(layout string, fieldMap logrus.FieldMap) (string, bool, logrus.FieldMap) {
+	m := logrus.FieldMap{}
+	for k, v := range fieldMap {
+		if k != logrus.FieldKeyTime || v != unusedTimeKey {
+			m[k] = v
+		}
+	}
+	if layout == TimestampUnixMillis {
+		m[logrus.FieldKeyTime] = unusedTimeKey
+		return "", true, m
+	}
+
+	return layout, false, m
+}
+
+const ecsVersion = "8.11.0"
+
+// ecsFormatter writes Elastic Common Schema JSON: the standard fields are
+// renamed (@timestamp, log.level, message) and error and caller details are
+// nested under error.* and log.origin.*.
+type ecsFormatter struct{}
+
+func (ecsFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	data := make(map[string]interface{}, len(entry.Data)+4)
+	for k, v := range entry.Data {
+		switch k {
+		case logrus.ErrorKey, "file", "func":
+			continue
+		}
+		data[k] = v
+	}
+
+	origin := map[string]interface{}{}
+	if file, ok := entry.Data["file"].(string); ok {
+		name, line := file, 0
+		if i := strings.LastIndex(file, ":"); i >= 0 {
+			if n, err := strconv.Atoi(file[i+1:]); err == nil {
+				name, line = file[:i], n
+			}
+		}
+		originFile := map[string]interface{}{"name": name}
+		if line > 0 {
+			originFile["line"] = line
+		}
+		origin["file"] = originFile
+	}
+	if fn, ok := entry.Data["func"].(string); ok {
+		origin["function"] = fn
+	}
+
+	logField := map[string]interface{}{"level": entry.Level.String()}
+	if len(origin) > 0 {
+		logField["origin"] = origin
+	}
+
+	if v, ok := entry.Data[logrus.ErrorKey]; ok && v != nil {
+		errField := map[string]interface{}{"message": fmt.Sprint(v)}
+		if err, ok := v.(error); ok {
+			errField["message"] = err.Error()
+			errField["type"] = fmt.Sprintf("%T", err)
+		}
+		data["error"] = errField
+	}
+
+	data["@timestamp"] = entry.Time.UTC().Format(time.RFC3339Nano)
+	data["log"] = logField
+	data["message"] = entry.Message
+	data["ecs"] = map[string]interface{}{"version": ecsVersion}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal fields to JSON, %w", err)
+	}
+
+	return append(b, '\n'), nil
+}
+
+var ecsPrevFormatter logrus.Formatter
+
+// SetECSFormat switches to Elastic Common Schema JSON output. Disabling it
+// restores the previous formatter.
+func SetECSFormat(enabled bool) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	_, active := log.Formatter.(ecsFormatter)
+	switch {
+	case enabled && !active:
+		ecsPrevFormatter = log.Formatter
+		log.SetFormatter(ecsFormatter{})
+	case !enabled && active:
+		applyFormatter(ecsPrevFormatter)
+		ecsPrevFormatter = nil
+	}
+}
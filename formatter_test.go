@@ -0,0 +1,334 @@
+package logruswrapper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestSetCompactFormat_ProducesCompactLine(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	SetCompactFormat(true)
+	defer SetCompactFormat(false)
+
+	entry := log.WithFields(Fields{"b": 2, "a": "x"})
+	entry.Info("hello")
+
+	if got, want := buf.String(), "I|hello|a=x,b=2\n"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestSetCompactFormat_RoundTripsFields(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	SetCompactFormat(true)
+	defer SetCompactFormat(false)
+
+	fields := Fields{"path": "/a|b", "query": "x=1,y=2", "device": "edge-7"}
+	Warn(context.Background(), "odd|message", &fields)
+
+	level, msg, parsed, err := ParseCompactLine(buf.String())
+	if err != nil {
+		t.Fatalf("expected compact line to parse, got %v", err)
+	}
+
+	if level != "W" {
+		t.Errorf("expected level 'W', got %q", level)
+	}
+	if msg != "odd|message" {
+		t.Errorf("expected msg 'odd|message', got %q", msg)
+	}
+	for k, v := range fields {
+		if parsed[k] != v {
+			t.Errorf("expected field %s=%v, got %q", k, v, parsed[k])
+		}
+	}
+	if _, ok := parsed["file"]; !ok {
+		t.Error("expected caller fields to be present")
+	}
+}
+
+func TestSetCompactFormat_DisableRestoresFormatter(t *testing.T) {
+	captureOutput()
+	defer restoreOutput()
+
+	SetCompactFormat(true)
+	SetCompactFormat(false)
+
+	if _, ok := log.Formatter.(*logrus.JSONFormatter); !ok {
+		t.Errorf("expected JSONFormatter to be restored, got %T", log.Formatter)
+	}
+}
+
+func TestSetFormatterFallback_WritesPlainTextOnMarshalFailure(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	SetFormatterFallback(true)
+	defer SetFormatterFallback(false)
+
+	// Bypasses generateLogger's sanitizing so the JSON formatter sees the value.
+	log.WithField("payload", failingMarshaler{}).WithError(errors.New("upstream")).Error("checkout failed")
+
+	line := buf.String()
+	if !strings.Contains(line, `msg="checkout failed"`) {
+		t.Errorf("expected fallback line with the message, got %q", line)
+	}
+	if !strings.Contains(line, "level=error") || !strings.Contains(line, `error="upstream"`) {
+		t.Errorf("expected level and error in fallback line, got %q", line)
+	}
+	if !strings.Contains(line, "format_error=") {
+		t.Errorf("expected format_error in fallback line, got %q", line)
+	}
+}
+
+func TestSetFormatterFallback_Disable(t *testing.T) {
+	captureOutput()
+	defer restoreOutput()
+
+	SetFormatterFallback(true)
+	SetFormatterFallback(false)
+
+	if _, ok := log.Formatter.(*logrus.JSONFormatter); !ok {
+		t.Errorf("expected JSONFormatter to be restored, got %T", log.Formatter)
+	}
+}
+
+type failingMarshaler struct{}
+
+func (failingMarshaler) MarshalJSON() ([]byte, error) {
+	return nil, errors.New("cannot marshal")
+}
+
+func TestSetFieldOrder_LeadingKeysInConfiguredOrder(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	SetFieldOrder("level", "time", "msg")
+	defer SetFieldOrder()
+
+	fields := Fields{"zone": "eu", "attempt": 2}
+	Info(context.Background(), "ordered", &fields)
+
+	line := buf.String()
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	if !strings.HasPrefix(line, `{"level":"info","time":`) {
+		t.Errorf("expected level and time first, got %s", line)
+	}
+	positions := []int{
+		strings.Index(line, `"msg":`),
+		strings.Index(line, `"attempt":`),
+		strings.Index(line, `"file":`),
+		strings.Index(line, `"zone":`),
+	}
+	for i := 1; i < len(positions); i++ {
+		if positions[i-1] < 0 || positions[i-1] > positions[i] {
+			t.Fatalf("expected msg then remaining keys alphabetically, got %s", line)
+		}
+	}
+}
+
+func TestSetFieldOrder_NoKeysRestoresFormatter(t *testing.T) {
+	captureOutput()
+	defer restoreOutput()
+
+	SetFieldOrder("msg")
+	SetFieldOrder()
+
+	if _, ok := log.Formatter.(*logrus.JSONFormatter); !ok {
+		t.Errorf("expected JSONFormatter to be restored, got %T", log.Formatter)
+	}
+}
+
+func TestSetECSFormat_ErrorLine(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	SetECSFormat(true)
+	defer SetECSFormat(false)
+
+	fields := Fields{"order_id": "o-1"}
+	Error(context.Background(), "payment failed", errors.New("card declined"), &fields)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	if _, ok := entry["@timestamp"].(string); !ok {
+		t.Errorf("expected @timestamp, got %v", entry)
+	}
+	if entry["message"] != "payment failed" {
+		t.Errorf("expected message 'payment failed', got %v", entry["message"])
+	}
+	for _, key := range []string{"msg", "level", "time", "file", "func"} {
+		if _, ok := entry[key]; ok {
+			t.Errorf("expected %q to be renamed in ECS output", key)
+		}
+	}
+
+	logField, _ := entry["log"].(map[string]interface{})
+	if logField["level"] != "error" {
+		t.Errorf("expected log.level 'error', got %v", logField["level"])
+	}
+	origin, _ := logField["origin"].(map[string]interface{})
+	originFile, _ := origin["file"].(map[string]interface{})
+	if originFile["name"] != "formatter_test.go" {
+		t.Errorf("expected log.origin.file.name 'formatter_test.go', got %v", originFile["name"])
+	}
+	if fn, _ := origin["function"].(string); !strings.HasSuffix(fn, "TestSetECSFormat_ErrorLine") {
+		t.Errorf("expected log.origin.function to be the test, got %v", origin["function"])
+	}
+
+	errField, _ := entry["error"].(map[string]interface{})
+	if errField["message"] != "card declined" {
+		t.Errorf("expected error.message 'card declined', got %v", errField["message"])
+	}
+	if entry["order_id"] != "o-1" {
+		t.Errorf("expected custom fields to be kept, got %v", entry["order_id"])
+	}
+}
+
+func TestSetTimestampFormat_Layout(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+	log.SetFormatter(&logrus.JSONFormatter{})
+
+	SetTimestampFormat(time.RFC3339Nano)
+	defer SetTimestampFormat("")
+
+	Info(context.Background(), "stamped", nil)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+	stamp, _ := entry["time"].(string)
+	if _, err := time.Parse(time.RFC3339Nano, stamp); err != nil {
+		t.Errorf("expected time in RFC3339Nano, got %q", stamp)
+	}
+}
+
+func TestSetTimestampFormat_UnixMillis(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+	log.SetFormatter(&logrus.JSONFormatter{})
+
+	SetTimestampFormat(TimestampUnixMillis)
+	defer SetTimestampFormat("")
+
+	before := time.Now().UnixMilli()
+	Info(context.Background(), "stamped", nil)
+	after := time.Now().UnixMilli()
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+	millis, ok := entry["time"].(float64)
+	if !ok {
+		t.Fatalf("expected numeric time, got %v", entry["time"])
+	}
+	if int64(millis) < before || int64(millis) > after {
+		t.Errorf("expected time between %d and %d, got %d", before, after, int64(millis))
+	}
+	if _, ok := entry["fields.time"]; ok {
+		t.Errorf("expected no fields.time clash, got %v", entry)
+	}
+
+	buf.Reset()
+	SetTimestampFormat(time.RFC3339)
+	Info(context.Background(), "stamped", nil)
+	entry = nil
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+	if _, ok := entry["time"].(string); !ok {
+		t.Errorf("expected string time after switching back, got %v", entry["time"])
+	}
+}
+
+func TestSetTimestampFormat_SurvivesSetup(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	resetOnce()
+	defer resetOnce()
+
+	SetTimestampFormat(TimestampUnixMillis)
+	defer SetTimestampFormat("")
+	Setup("info", true)
+
+	Info(context.Background(), "stamped", nil)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+	if _, ok := entry["time"].(float64); !ok {
+		t.Errorf("expected numeric time after Setup, got %v", entry["time"])
+	}
+	if _, ok := entry["fields.time"]; ok {
+		t.Errorf("expected no fields.time clash, got %v", entry)
+	}
+}
+
+func TestSetTimestampFormat_ECSKeepsOwnTimestamp(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	SetTimestampFormat(TimestampUnixMillis)
+	defer SetTimestampFormat("")
+	SetECSFormat(true)
+	defer SetECSFormat(false)
+
+	Info(context.Background(), "stamped", nil)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+	if _, ok := entry["@timestamp"]; !ok {
+		t.Errorf("expected @timestamp, got %v", entry)
+	}
+	if _, ok := entry["time"]; ok {
+		t.Errorf("expected no top-level time next to @timestamp, got %v", entry)
+	}
+}
+
+func TestSetIncludeEntrySize_CountsLineBytes(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+	log.SetFormatter(&logrus.JSONFormatter{})
+
+	SetIncludeEntrySize(true)
+	defer SetIncludeEntrySize(false)
+
+	before := LoggedBytes()
+	Info(context.Background(), "sized", &Fields{"payload": strings.Repeat("x", 200)})
+
+	if got := LoggedBytes() - before; got != uint64(buf.Len()) {
+		t.Errorf("expected %d bytes counted, got %d", buf.Len(), got)
+	}
+}
@@ -0,0 +1,218 @@
+package logruswrapper
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+)
+
+// InfoCollection logs a slice, array or map with its total "count" and a
+// "sample" of at most sampleSize elements. Map samples use the smallest keys.
+func InfoCollection(ctx context.Context, msg string, items interface{}, sampleSize int) {
+	if !enabled(ctx, logrus.InfoLevel, msg) {
+		return
+	}
+
+	fields := Fields{}
+	if sampleSize < 0 {
+		sampleSize = 0
+	}
+
+	v := reflect.ValueOf(items)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		n := min(v.Len(), sampleSize)
+		sample := make([]interface{}, n)
+		for i := 0; i < n; i++ {
+			sample[i] = v.Index(i).Interface()
+		}
+		fields["count"] = v.Len()
+		fields["sample"] = sample
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		n := min(len(keys), sampleSize)
+		sample := make(map[string]interface{}, n)
+		for _, k := range keys[:n] {
+			sample[fmt.Sprint(k.Interface())] = v.MapIndex(k).Interface()
+		}
+		fields["count"] = v.Len()
+		fields["sample"] = sample
+	default:
+		fields["invalid_collection"] = true
+	}
+
+	callerFields := getCaller(logrus.InfoLevel)
+	generateLogger(ctx, &fields).WithFields(*callerFields).Info(msg)
+}
+
+// LogValidationErrors emits a single Warn line carrying every field error
+// under "validation_errors" along with their count.
+func LogValidationErrors(ctx context.Context, errs map[string]string) {
+	if !enabled(ctx, logrus.WarnLevel, "validation failed") {
+		return
+	}
+
+	fields := Fields{
+		"validation_errors":      errs,
+		"validation_error_count": len(errs),
+	}
+
+	callerFields := getCaller(logrus.WarnLevel)
+	generateLogger(ctx, &fields).WithFields(*callerFields).Warn("validation failed")
+}
+
+// Event logs an analytics-style event at Info with msg "event" and the name
+// in the "event" field. An empty name is logged at Warn instead so the
+// offending call site can be found.
+func Event(ctx context.Context, name string, fields Fields) {
+	level, msg := logrus.InfoLevel, "event"
+	if name == "" {
+		level, msg = logrus.WarnLevel, "event logged without a name"
+	}
+	if !enabled(ctx, level, sampleKey(msg, name)) {
+		return
+	}
+
+	eventFields := make(Fields, len(fields)+1)
+	for k, v := range fields {
+		eventFields[k] = v
+	}
+	eventFields["event"] = name
+
+	callerFields := getCaller(level)
+	entry := generateLogger(ctx, &eventFields).WithFields(*callerFields)
+	entry.Log(level, msg)
+}
+
+// LifecyclePhase is a step in a component's startup or shutdown.
+type LifecyclePhase int
+
+const (
+	LifecycleStarting LifecyclePhase = iota
+	LifecycleStarted
+	LifecycleStopping
+	LifecycleStopped
+)
+
+func (p LifecyclePhase) String() string {
+	switch p {
+	case LifecycleStarting:
+		return "starting"
+	case LifecycleStarted:
+		return "started"
+	case LifecycleStopping:
+		return "stopping"
+	case LifecycleStopped:
+		return "stopped"
+	}
+
+	return fmt.Sprintf("LifecyclePhase(%d)", int(p))
+}
+
+// Lifecycle logs "<component> <phase>" at Info with "component" and "phase"
+// fields so startup and shutdown lines read and query the same everywhere.
+func Lifecycle(ctx context.Context, component string, phase LifecyclePhase, fields *Fields) {
+	msg := component + " " + phase.String()
+	if !enabled(ctx, logrus.InfoLevel, msg) {
+		return
+	}
+
+	lifecycleFields := Fields{}
+	if fields != nil {
+		for k, v := range *fields {
+			lifecycleFields[k] = v
+		}
+	}
+	lifecycleFields["component"] = component
+	lifecycleFields["phase"] = phase.String()
+
+	callerFields := getCaller(logrus.InfoLevel)
+	generateLogger(ctx, &lifecycleFields).WithFields(*callerFields).Info(msg)
+}
+
+// LogDiff logs msg at Info with a "changes" field mapping each exported
+// struct field that differs between oldValue and newValue to its
+// {"old", "new"} pair. Both values must be structs (or pointers to structs)
+// of the same type; otherwise the line carries "invalid_diff" instead.
+func LogDiff(ctx context.Context, msg string, oldValue, newValue interface{}) {
+	if !enabled(ctx, logrus.InfoLevel, msg) {
+		return
+	}
+
+	fields := Fields{}
+	if changes, ok := structDiff(oldValue, newValue); ok {
+		fields["changes"] = changes
+	} else {
+		fields["invalid_diff"] = true
+	}
+
+	callerFields := getCaller(logrus.InfoLevel)
+	generateLogger(ctx, &fields).WithFields(*callerFields).Info(msg)
+}
+
+func structDiff(oldValue, newValue interface{}) (map[string]interface{}, bool) {
+	ov, nv := reflect.ValueOf(oldValue), reflect.ValueOf(newValue)
+	for ov.Kind() == reflect.Pointer && !ov.IsNil() {
+		ov = ov.Elem()
+	}
+	for nv.Kind() == reflect.Pointer && !nv.IsNil() {
+		nv = nv.Elem()
+	}
+	if ov.Kind() != reflect.Struct || nv.Kind() != reflect.Struct || ov.Type() != nv.Type() {
+		return nil, false
+	}
+
+	changes := map[string]interface{}{}
+	for i := 0; i < ov.NumField(); i++ {
+		if !ov.Type().Field(i).IsExported() {
+			continue
+		}
+		o, n := ov.Field(i).Interface(), nv.Field(i).Interface()
+		if !reflect.DeepEqual(o, n) {
+			changes[ov.Type().Field(i).Name] = map[string]interface{}{"old": o, "new": n}
+		}
+	}
+
+	return changes, true
+}
+
+// Errors logs msg once for a batch of errors: at Error with their messages
+// under "errors" and their number under "error_count". Nil entries are
+// skipped; if every entry is nil the line is logged at Info instead.
+func Errors(ctx context.Context, msg string, fields *Fields, errs []error) {
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		if err != nil {
+			messages = append(messages, err.Error())
+		}
+	}
+
+	level := logrus.ErrorLevel
+	if len(messages) == 0 {
+		level = logrus.InfoLevel
+	}
+	if !enabled(ctx, level, msg) {
+		return
+	}
+
+	errorFields := Fields{}
+	if fields != nil {
+		for k, v := range *fields {
+			errorFields[k] = v
+		}
+	}
+	errorFields["errors"] = messages
+	errorFields["error_count"] = len(messages)
+
+	callerFields := getCaller(level)
+	generateLogger(ctx, &errorFields).WithFields(*callerFields).Log(level, msg)
+	if level == logrus.ErrorLevel {
+		errorLogged(level)
+	}
+}
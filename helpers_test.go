@@ -0,0 +1,284 @@
+package logruswrapper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestInfoCollection_Slice(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	InfoCollection(context.Background(), "processed ids", []int{10, 20, 30, 40, 50}, 2)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	if entry["count"] != float64(5) {
+		t.Errorf("expected count 5, got %v", entry["count"])
+	}
+	sample, ok := entry["sample"].([]interface{})
+	if !ok || len(sample) != 2 {
+		t.Fatalf("expected sample of 2 elements, got %v", entry["sample"])
+	}
+	if sample[0] != float64(10) || sample[1] != float64(20) {
+		t.Errorf("expected sample [10 20], got %v", sample)
+	}
+}
+
+func TestInfoCollection_Map(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	items := map[string]int{"c": 3, "a": 1, "b": 2}
+	InfoCollection(context.Background(), "quotas", items, 2)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	if entry["count"] != float64(3) {
+		t.Errorf("expected count 3, got %v", entry["count"])
+	}
+	sample, ok := entry["sample"].(map[string]interface{})
+	if !ok || len(sample) != 2 {
+		t.Fatalf("expected sample of 2 entries, got %v", entry["sample"])
+	}
+	if sample["a"] != float64(1) || sample["b"] != float64(2) {
+		t.Errorf("expected sample {a:1 b:2}, got %v", sample)
+	}
+}
+
+func TestInfoCollection_NonCollection(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	InfoCollection(context.Background(), "not a collection", 42, 2)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	if entry["invalid_collection"] != true {
+		t.Errorf("expected invalid_collection marker, got %v", entry["invalid_collection"])
+	}
+	if _, ok := entry["count"]; ok {
+		t.Error("expected no count field for a non-collection")
+	}
+}
+
+func TestLogValidationErrors(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	LogValidationErrors(context.Background(), map[string]string{
+		"email": "must be a valid address",
+		"age":   "must be positive",
+	})
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	if entry["level"] != "warning" {
+		t.Errorf("expected level 'warning', got %v", entry["level"])
+	}
+	if entry["validation_error_count"] != float64(2) {
+		t.Errorf("expected validation_error_count 2, got %v", entry["validation_error_count"])
+	}
+	errs, ok := entry["validation_errors"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected validation_errors object, got %v", entry["validation_errors"])
+	}
+	if errs["email"] != "must be a valid address" || errs["age"] != "must be positive" {
+		t.Errorf("expected validation errors to be preserved, got %v", errs)
+	}
+}
+
+func TestEvent(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	Event(context.Background(), "checkout.completed", Fields{"amount": 42})
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	if entry["level"] != "info" || entry["msg"] != "event" {
+		t.Errorf("expected info line with msg 'event', got %v", entry)
+	}
+	if entry["event"] != "checkout.completed" {
+		t.Errorf("expected event 'checkout.completed', got %v", entry["event"])
+	}
+	if entry["amount"] != float64(42) {
+		t.Errorf("expected amount field, got %v", entry["amount"])
+	}
+}
+
+func TestEvent_EmptyName(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	Event(context.Background(), "", Fields{"amount": 42})
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	if entry["level"] != "warning" {
+		t.Errorf("expected level 'warning' for an empty event name, got %v", entry["level"])
+	}
+	if entry["msg"] != "event logged without a name" {
+		t.Errorf("expected fallback message, got %v", entry["msg"])
+	}
+}
+
+func TestLifecycle(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	tests := []struct {
+		phase LifecyclePhase
+		msg   string
+		field string
+	}{
+		{LifecycleStarting, "http-server starting", "starting"},
+		{LifecycleStarted, "http-server started", "started"},
+		{LifecycleStopping, "http-server stopping", "stopping"},
+		{LifecycleStopped, "http-server stopped", "stopped"},
+	}
+
+	for _, tt := range tests {
+		buf.Reset()
+		Lifecycle(context.Background(), "http-server", tt.phase, &Fields{"port": 8080})
+
+		var entry map[string]interface{}
+		if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+			t.Fatalf("expected valid JSON output: %v", err)
+		}
+		if entry["level"] != "info" || entry["msg"] != tt.msg {
+			t.Errorf("expected info line %q, got %v", tt.msg, entry)
+		}
+		if entry["phase"] != tt.field {
+			t.Errorf("expected phase %q, got %v", tt.field, entry["phase"])
+		}
+		if entry["component"] != "http-server" || entry["port"] != float64(8080) {
+			t.Errorf("expected component and port fields, got %v", entry)
+		}
+	}
+}
+
+type diffConfig struct {
+	Host    string
+	Port    int
+	Tags    []string
+	Timeout int
+	secret  string
+}
+
+func TestLogDiff_OnlyChangedFields(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	before := diffConfig{Host: "db1", Port: 5432, Tags: []string{"a"}, Timeout: 5, secret: "x"}
+	after := diffConfig{Host: "db2", Port: 5432, Tags: []string{"a"}, Timeout: 10, secret: "y"}
+	LogDiff(context.Background(), "config reloaded", before, &after)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	changes, ok := entry["changes"].(map[string]interface{})
+	if !ok || len(changes) != 2 {
+		t.Fatalf("expected exactly 2 changes, got %v", entry["changes"])
+	}
+	host, _ := changes["Host"].(map[string]interface{})
+	if host["old"] != "db1" || host["new"] != "db2" {
+		t.Errorf("expected Host db1 -> db2, got %v", changes["Host"])
+	}
+	timeout, _ := changes["Timeout"].(map[string]interface{})
+	if timeout["old"] != float64(5) || timeout["new"] != float64(10) {
+		t.Errorf("expected Timeout 5 -> 10, got %v", changes["Timeout"])
+	}
+}
+
+func TestLogDiff_NonStruct(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	LogDiff(context.Background(), "config reloaded", "old", 42)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+	if entry["invalid_diff"] != true {
+		t.Errorf("expected invalid_diff marker, got %v", entry)
+	}
+	if _, ok := entry["changes"]; ok {
+		t.Error("expected no changes field for non-struct inputs")
+	}
+}
+
+func TestErrors_FiltersNil(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	errs := []error{errors.New("row 1 invalid"), nil, errors.New("row 3 invalid"), nil}
+	Errors(context.Background(), "batch import failed", &Fields{"batch": 7}, errs)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	if entry["level"] != "error" || entry["error_count"] != float64(2) {
+		t.Errorf("expected error line with error_count 2, got %v", entry)
+	}
+	messages, _ := entry["errors"].([]interface{})
+	if len(messages) != 2 || messages[0] != "row 1 invalid" || messages[1] != "row 3 invalid" {
+		t.Errorf("expected the two non-nil messages, got %v", entry["errors"])
+	}
+	if entry["batch"] != float64(7) {
+		t.Errorf("expected batch field, got %v", entry["batch"])
+	}
+}
+
+func TestErrors_AllNilDowngradesToInfo(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	Errors(context.Background(), "batch import finished", nil, []error{nil, nil})
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+	if entry["level"] != "info" || entry["error_count"] != float64(0) {
+		t.Errorf("expected info line with error_count 0, got %v", entry)
+	}
+}
@@ -0,0 +1,58 @@
+package logruswrapper
+
+import "github.com/sirupsen/logrus"
+
+// AddHook registers hook on the package logger, after the wrapper's own
+// hooks, so it sees entries with the package options already applied.
+func AddHook(hook logrus.Hook) {
+	addHook(hook)
+}
+
+// RemoveHook unregisters a hook added with AddHook.
+func RemoveHook(hook logrus.Hook) {
+	removeHook(hook)
+}
+
+func addHook(hook logrus.Hook) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	log.AddHook(hook)
+}
+
+func removeHook(hook logrus.Hook) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	hooks := make(logrus.LevelHooks)
+	for level, levelHooks := range log.Hooks {
+		for _, h := range levelHooks {
+			if h != hook {
+				hooks[level] = append(hooks[level], h)
+			}
+		}
+	}
+	log.ReplaceHooks(hooks)
+}
+
+// SetEntryObserver calls fn with every entry that is written, after the
+// wrapper's own processing and just before formatting. fn must not modify
+// the entry and may be called from several goroutines at once. Passing nil
+// removes the observer.
+func SetEntryObserver(fn func(*logrus.Entry)) {
+	updateOptions(func(o *options) { o.observer = fn })
+}
+
+type observerHook struct{}
+
+func (observerHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (observerHook) Fire(entry *logrus.Entry) error {
+	if fn := loadOptions().observer; fn != nil {
+		fn(entry)
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package logruswrapper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestSetEntryObserver_SeesEmittedEntries(t *testing.T) {
+	captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	var (
+		seenMu sync.Mutex
+		seen   []string
+	)
+	SetEntryObserver(func(e *logrus.Entry) {
+		seenMu.Lock()
+		defer seenMu.Unlock()
+		seen = append(seen, e.Level.String()+":"+e.Message)
+	})
+	defer SetEntryObserver(nil)
+
+	ctx := context.Background()
+	Info(ctx, "hello", nil)
+	Error(ctx, "failed", errors.New("boom"), nil)
+	Debug(ctx, "suppressed", nil)
+
+	want := []string{"info:hello", "error:failed"}
+	if fmt.Sprint(seen) != fmt.Sprint(want) {
+		t.Errorf("expected observer to see %v, got %v", want, seen)
+	}
+}
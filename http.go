@@ -0,0 +1,189 @@
+package logruswrapper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type levelResponse struct {
+	Level string `json:"level"`
+	Error string `json:"error,omitempty"`
+}
+
+// LevelHandler reports the current level on GET and changes it on PUT/POST
+// using the "level" query or form value.
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			if err := Reconfigure(r.FormValue("level")); err != nil {
+				writeLevelResponse(w, http.StatusBadRequest, levelResponse{Level: GetLevel(), Error: err.Error()})
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		writeLevelResponse(w, http.StatusOK, levelResponse{Level: GetLevel()})
+	})
+}
+
+func writeLevelResponse(w http.ResponseWriter, status int, body levelResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// LogHTTPRoundTrip logs req and resp at Debug with bodies truncated to
+// maxBytes. Bodies are restored so they can still be read afterwards.
+func LogHTTPRoundTrip(ctx context.Context, req *http.Request, resp *http.Response, maxBytes int) {
+	if !enabled(ctx, logrus.DebugLevel, "http round trip") {
+		return
+	}
+
+	if req == nil && resp != nil {
+		req = resp.Request
+	}
+
+	fields := Fields{}
+	if req != nil {
+		fields["method"] = req.Method
+		if req.URL != nil {
+			fields["url"] = req.URL.String()
+		}
+		body, truncated := peekBody(&req.Body, maxBytes)
+		fields["request_body"] = body
+		if truncated {
+			fields["request_body_truncated"] = true
+		}
+	}
+	if resp != nil {
+		fields["status"] = resp.StatusCode
+		body, truncated := peekBody(&resp.Body, maxBytes)
+		fields["response_body"] = body
+		if truncated {
+			fields["response_body_truncated"] = true
+		}
+	}
+
+	callerFields := getCaller(logrus.DebugLevel)
+	generateLogger(ctx, &fields).WithFields(*callerFields).Debug("http round trip")
+}
+
+// peekBody reads up to maxBytes from *body and replaces it with a reader that
+// yields the full original content.
+func peekBody(body *io.ReadCloser, maxBytes int) (string, bool) {
+	if *body == nil || *body == http.NoBody {
+		return "", false
+	}
+	if maxBytes < 0 {
+		maxBytes = 0
+	}
+
+	prefix, _ := io.ReadAll(io.LimitReader(*body, int64(maxBytes)+1))
+	*body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(prefix), *body), *body}
+
+	if len(prefix) > maxBytes {
+		return string(prefix[:maxBytes]), true
+	}
+
+	return string(prefix), false
+}
+
+// NewLoggingRoundTripper wraps next (http.DefaultTransport when nil) so that
+// every outbound request is logged with its method, host, path, status and
+// duration: at Info on a response, at Error on a transport failure. The
+// request context is used, so its fields are attached. Bodies are not read.
+func NewLoggingRoundTripper(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return loggingRoundTripper{next: next}
+}
+
+type loggingRoundTripper struct {
+	next http.RoundTripper
+}
+
+func (rt loggingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := rt.next.RoundTrip(req)
+	elapsed := time.Since(start)
+
+	ctx := req.Context()
+	level := logrus.InfoLevel
+	if err != nil {
+		level = logrus.ErrorLevel
+	}
+	if !enabled(ctx, level, "http request") {
+		return resp, err
+	}
+
+	fields := Fields{
+		"method":      req.Method,
+		"host":        req.URL.Host,
+		"path":        req.URL.Path,
+		"duration_ms": elapsed.Milliseconds(),
+	}
+	if resp != nil {
+		fields["status"] = resp.StatusCode
+	}
+
+	entry := generateLogger(ctx, &fields)
+	if err != nil {
+		logError(entry, "http request failed", err)
+		return resp, err
+	}
+	entry.Info("http request")
+
+	return resp, nil
+}
+
+// RecoveryMiddleware recovers panics from next, logs them at Error with the
+// "stacktrace" and the request context's fields, and responds with 500.
+// http.ErrAbortHandler is re-panicked so net/http can abort the response.
+func RecoveryMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+
+			if enabled(r.Context(), logrus.ErrorLevel, "panic recovered") {
+				err, ok := rec.(error)
+				if !ok {
+					err = fmt.Errorf("%v", rec)
+				}
+				fields := Fields{
+					"method":     r.Method,
+					"path":       r.URL.Path,
+					"stacktrace": string(debug.Stack()),
+				}
+				logError(generateLogger(r.Context(), &fields), "panic recovered", err)
+			}
+
+			w.WriteHeader(http.StatusInternalServerError)
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,297 @@
+package logruswrapper
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func decodeLevelResponse(t *testing.T, rec *httptest.ResponseRecorder) levelResponse {
+	t.Helper()
+	var body levelResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("expected valid JSON response: %v", err)
+	}
+	return body
+}
+
+func TestLevelHandler_Get(t *testing.T) {
+	defer setLevel(logrus.InfoLevel)
+	setLevel(logrus.WarnLevel)
+
+	rec := httptest.NewRecorder()
+	LevelHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/log/level", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if body := decodeLevelResponse(t, rec); body.Level != "warning" {
+		t.Errorf("expected level 'warning', got %q", body.Level)
+	}
+}
+
+func TestLevelHandler_SetViaQuery(t *testing.T) {
+	defer setLevel(logrus.InfoLevel)
+	setLevel(logrus.InfoLevel)
+
+	rec := httptest.NewRecorder()
+	LevelHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/log/level?level=debug", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if currentLevel() != logrus.DebugLevel {
+		t.Errorf("expected level %v, got %v", logrus.DebugLevel, currentLevel())
+	}
+	if body := decodeLevelResponse(t, rec); body.Level != "debug" {
+		t.Errorf("expected response level 'debug', got %q", body.Level)
+	}
+}
+
+func TestLevelHandler_SetViaBody(t *testing.T) {
+	defer setLevel(logrus.InfoLevel)
+	setLevel(logrus.InfoLevel)
+
+	form := url.Values{"level": {"error"}}
+	req := httptest.NewRequest(http.MethodPost, "/log/level", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	LevelHandler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if currentLevel() != logrus.ErrorLevel {
+		t.Errorf("expected level %v, got %v", logrus.ErrorLevel, currentLevel())
+	}
+}
+
+func TestLevelHandler_InvalidLevel(t *testing.T) {
+	defer setLevel(logrus.InfoLevel)
+	setLevel(logrus.InfoLevel)
+
+	rec := httptest.NewRecorder()
+	LevelHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/log/level?level=loud", nil))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+	if currentLevel() != logrus.InfoLevel {
+		t.Errorf("expected level to remain %v, got %v", logrus.InfoLevel, currentLevel())
+	}
+	if body := decodeLevelResponse(t, rec); body.Error == "" {
+		t.Error("expected an error message in the response")
+	}
+}
+
+func TestLogHTTPRoundTrip_TruncatesAndRestoresBodies(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.DebugLevel)
+	defer setLevel(logrus.InfoLevel)
+
+	req := httptest.NewRequest(http.MethodPost, "http://api.example.com/orders", strings.NewReader(`{"sku":"abc-123"}`))
+	resp := &http.Response{
+		StatusCode: http.StatusCreated,
+		Body:       io.NopCloser(strings.NewReader(`{"id":"order-1"}`)),
+	}
+
+	LogHTTPRoundTrip(context.Background(), req, resp, 8)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	if entry["method"] != http.MethodPost {
+		t.Errorf("expected method POST, got %v", entry["method"])
+	}
+	if entry["url"] != "http://api.example.com/orders" {
+		t.Errorf("expected url field, got %v", entry["url"])
+	}
+	if entry["status"] != float64(http.StatusCreated) {
+		t.Errorf("expected status 201, got %v", entry["status"])
+	}
+	if entry["request_body"] != `{"sku":"` {
+		t.Errorf("expected truncated request body, got %v", entry["request_body"])
+	}
+	if entry["request_body_truncated"] != true {
+		t.Error("expected request_body_truncated marker")
+	}
+	if entry["response_body"] != `{"id":"o` {
+		t.Errorf("expected truncated response body, got %v", entry["response_body"])
+	}
+
+	reqBody, _ := io.ReadAll(req.Body)
+	if string(reqBody) != `{"sku":"abc-123"}` {
+		t.Errorf("expected request body to remain readable, got %q", reqBody)
+	}
+	respBody, _ := io.ReadAll(resp.Body)
+	if string(respBody) != `{"id":"order-1"}` {
+		t.Errorf("expected response body to remain readable, got %q", respBody)
+	}
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r    io.Reader
+	read int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.read += n
+	return n, err
+}
+
+func TestLogHTTPRoundTrip_DisabledDoesNotReadBodies(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	body := &countingReader{r: strings.NewReader(`{"sku":"abc-123"}`)}
+	req := httptest.NewRequest(http.MethodPost, "http://api.example.com/orders", body)
+
+	LogHTTPRoundTrip(context.Background(), req, nil, 8)
+
+	if buf.Len() != 0 {
+		t.Errorf("expected no output at Info, got %s", buf.String())
+	}
+	if body.read != 0 {
+		t.Errorf("expected the request body not to be read, %d bytes were", body.read)
+	}
+}
+
+func TestLoggingRoundTripper_Success(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewLoggingRoundTripper(nil)}
+	ctx := ContextWithFields(context.Background(), Fields{"request_id": "abc"})
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"/orders?id=1", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	serverURL, _ := url.Parse(server.URL)
+	if entry["level"] != "info" || entry["msg"] != "http request" {
+		t.Errorf("expected info 'http request', got %v", entry)
+	}
+	if entry["method"] != "POST" || entry["host"] != serverURL.Host || entry["path"] != "/orders" {
+		t.Errorf("expected method, host and path fields, got %v", entry)
+	}
+	if entry["status"] != float64(http.StatusCreated) {
+		t.Errorf("expected status 201, got %v", entry["status"])
+	}
+	if _, ok := entry["duration_ms"].(float64); !ok {
+		t.Errorf("expected duration_ms, got %v", entry["duration_ms"])
+	}
+	if entry["request_id"] != "abc" {
+		t.Errorf("expected context fields from the request, got %v", entry["request_id"])
+	}
+}
+
+func TestLoggingRoundTripper_TransportError(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	addr := server.URL
+	server.Close()
+
+	client := &http.Client{Transport: NewLoggingRoundTripper(nil)}
+	resp, err := client.Get(addr + "/gone")
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("expected a transport error from a closed server")
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	if entry["level"] != "error" || entry["msg"] != "http request failed" {
+		t.Errorf("expected error 'http request failed', got %v", entry)
+	}
+	if entry["path"] != "/gone" || entry["error"] == nil {
+		t.Errorf("expected path and error fields, got %v", entry)
+	}
+	if _, ok := entry["status"]; ok {
+		t.Errorf("expected no status without a response, got %v", entry["status"])
+	}
+}
+
+func TestRecoveryMiddleware_SuppressedStillReturns500(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.FatalLevel)
+
+	handler := RecoveryMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("nil map write")
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/boom", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", rec.Code)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected no output at Fatal level, got %q", buf.String())
+	}
+}
+
+func TestRecoveryMiddleware_LogsAndReturns500(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	handler := RecoveryMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("nil map write")
+	}))
+
+	ctx := ContextWithFields(context.Background(), Fields{"request_id": "abc"})
+	req := httptest.NewRequest(http.MethodGet, "/boom", nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", rec.Code)
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+	if entry["level"] != "error" || entry["error"] != "nil map write" {
+		t.Errorf("expected error line for the panic, got %v", entry)
+	}
+	if stack, _ := entry["stacktrace"].(string); !strings.Contains(stack, "TestRecoveryMiddleware_LogsAndReturns500") {
+		t.Errorf("expected stacktrace through the handler, got %q", stack)
+	}
+	if entry["request_id"] != "abc" || entry["path"] != "/boom" {
+		t.Errorf("expected request context and path fields, got %v", entry)
+	}
+}
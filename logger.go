@@ -0,0 +1,74 @@
+package logruswrapper
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Logger is a named child logger. Every line it writes carries a "logger"
+// field with its dot-separated name, e.g. "db.pool".
+type Logger struct {
+	name string
+}
+
+func Named(name string) *Logger {
+	return &Logger{name: name}
+}
+
+// Named returns a child logger whose name is appended to l's with a dot.
+func (l *Logger) Named(name string) *Logger {
+	if l.name == "" {
+		return Named(name)
+	}
+
+	return &Logger{name: l.name + "." + name}
+}
+
+func (l *Logger) Name() string {
+	return l.name
+}
+
+func (l *Logger) withName(fields *Fields) *Fields {
+	named := Fields{}
+	if fields != nil {
+		for k, v := range *fields {
+			named[k] = v
+		}
+	}
+	named["logger"] = l.name
+
+	return &named
+}
+
+func (l *Logger) Info(ctx context.Context, msg string, fields *Fields) {
+	if !enabled(ctx, logrus.InfoLevel, msg) {
+		return
+	}
+	callerFields := getCaller(logrus.InfoLevel)
+	generateLogger(ctx, l.withName(fields)).WithFields(*callerFields).Info(msg)
+}
+
+func (l *Logger) Error(ctx context.Context, msg string, err error, fields *Fields) {
+	if !enabled(ctx, logrus.ErrorLevel, msg) {
+		return
+	}
+	callerFields := getCaller(logrus.ErrorLevel)
+	logError(generateLogger(ctx, mergeErrorFields(err, l.withName(fields))).WithFields(*callerFields), msg, err)
+}
+
+func (l *Logger) Debug(ctx context.Context, msg string, fields *Fields) {
+	if !enabled(ctx, logrus.DebugLevel, msg) {
+		return
+	}
+	callerFields := getCaller(logrus.DebugLevel)
+	generateLogger(ctx, l.withName(fields)).WithFields(*callerFields).Debug(msg)
+}
+
+func (l *Logger) Warn(ctx context.Context, msg string, fields *Fields) {
+	if !enabled(ctx, logrus.WarnLevel, msg) {
+		return
+	}
+	callerFields := getCaller(logrus.WarnLevel)
+	generateLogger(ctx, l.withName(fields)).WithFields(*callerFields).Warn(msg)
+}
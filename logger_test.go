@@ -0,0 +1,56 @@
+package logruswrapper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestNamed_DotJoinsNestedNames(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	pool := Named("db").Named("pool")
+	fields := Fields{"conns": 8}
+	pool.Info(context.Background(), "pool resized", &fields)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	if entry["logger"] != "db.pool" {
+		t.Errorf("expected logger 'db.pool', got %v", entry["logger"])
+	}
+	if entry["conns"] != float64(8) {
+		t.Errorf("expected per-call fields to be kept, got %v", entry["conns"])
+	}
+	if file, _ := entry["file"].(string); !strings.HasPrefix(file, "logger_test.go:") {
+		t.Errorf("expected caller to be the test, got %v", entry["file"])
+	}
+	if _, ok := fields["logger"]; ok {
+		t.Error("expected caller's fields map to be left untouched")
+	}
+}
+
+func TestNamed_Error(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	Named("http").Named("server").Error(context.Background(), "listen failed", errors.New("address in use"), nil)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	if entry["logger"] != "http.server" || entry["error"] != "address in use" {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+}
@@ -2,11 +2,13 @@ package logruswrapper
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -15,14 +17,43 @@ import (
 var (
 	log  *logrus.Logger
 	once sync.Once
+	// mu guards every change to log (formatter, output, hooks) and the
+	// reads of its fields made outside of logrus. The level is not one of
+	// them: it lives in globalLevel, which is atomic and read without mu.
+	mu sync.Mutex
+
+	// globalLevel is the level set through Setup or Reconfigure.
+	// log itself stays at TraceLevel so lines enabled by a level scoped with
+	// WithLevel or SetPackageLevel are still written through it, which means
+	// every write must be checked with enabled, enabledAt or levelFor first.
+	globalLevel atomic.Uint32
+
+	runtimeCaller = runtime.Caller
 )
 
 type Fields = logrus.Fields
 
+type fielder interface {
+	Fields() map[string]interface{}
+}
+
+type temporary interface {
+	Temporary() bool
+}
+
+type retryable interface {
+	Retryable() bool
+}
+
 func init() {
 	log = logrus.New()
+	log.SetLevel(logrus.TraceLevel)
+	setLevel(logrus.InfoLevel)
 	log.SetOutput(os.Stdout)
 	log.SetFormatter(&logrus.JSONFormatter{})
+	log.AddHook(optionsHook{})
+	log.AddHook(levelCountHook{})
+	log.AddHook(observerHook{})
 }
 
 func Setup(level string, isProduction bool) {
@@ -31,71 +62,415 @@ func Setup(level string, isProduction bool) {
 		if err != nil {
 			lvl = logrus.InfoLevel
 		}
-		log.SetLevel(lvl)
-
-		if isProduction {
-			log.SetFormatter(&logrus.JSONFormatter{
-				TimestampFormat: time.RFC3339,
-			})
-		} else {
-			log.SetFormatter(&logrus.TextFormatter{
-				FullTimestamp:   true,
-				TimestampFormat: time.RFC3339,
-				ForceColors:     true,
-			})
-		}
+		configure(lvl, isProduction)
 	})
 }
 
+// SetupE is like Setup but rejects an invalid level instead of defaulting to
+// Info. Nothing is applied when it returns an error.
+func SetupE(level string, isProduction bool) error {
+	lvl, err := logrus.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	once.Do(func() {
+		configure(lvl, isProduction)
+	})
+
+	return nil
+}
+
+func configure(lvl logrus.Level, isProduction bool) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	setLevel(lvl)
+
+	if isProduction {
+		applyFormatter(&logrus.JSONFormatter{
+			TimestampFormat: time.RFC3339,
+		})
+	} else {
+		applyFormatter(&logrus.TextFormatter{
+			FullTimestamp:   true,
+			TimestampFormat: time.RFC3339,
+			ForceColors:     true,
+		})
+	}
+}
+
+func Reconfigure(level string) error {
+	lvl, err := logrus.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	setLevel(lvl)
+
+	return nil
+}
+
+func GetLevel() string {
+	return currentLevel().String()
+}
+
+func setLevel(lvl logrus.Level) {
+	globalLevel.Store(uint32(lvl))
+}
+
+func currentLevel() logrus.Level {
+	return logrus.Level(globalLevel.Load())
+}
+
 func generateLogger(ctx context.Context, fields *Fields) *logrus.Entry {
-	entry := logrus.WithContext(ctx)
-	if fields != nil {
-		entry = entry.WithFields(*fields)
+	entry := log.WithContext(ctx)
+	if nestKey := loadOptions().nestFieldsKey; nestKey != "" {
+		nested := Fields{}
+		for _, layer := range []Fields{contextFields(ctx), extractFields(ctx), derefFields(fields)} {
+			for k, v := range prepareFields(layer) {
+				nested[k] = v
+			}
+		}
+		if len(nested) > 0 {
+			entry = entry.WithField(nestKey, nested)
+		}
+	} else {
+		if ctxFields := contextFields(ctx); len(ctxFields) > 0 {
+			entry = entry.WithFields(prepareFields(ctxFields))
+		}
+		if extracted := extractFields(ctx); len(extracted) > 0 {
+			entry = entry.WithFields(prepareFields(extracted))
+		}
+		if fields != nil && len(*fields) > 0 {
+			entry = entry.WithFields(prepareFields(*fields))
+		}
+	}
+
+	for _, mw := range loadOptions().middlewares {
+		entry = mw(entry)
 	}
 
 	return entry
 }
 
-func getCaller() *logrus.Fields {
-	pc, file, line, ok := runtime.Caller(2)
-	if !ok {
+// Use appends mw to the chain of middleware run, in registration order, on
+// every entry built for a log call. Each middleware returns the entry to
+// pass on, typically extended with entry.WithField or WithFields.
+func Use(mw func(*logrus.Entry) *logrus.Entry) {
+	updateOptions(func(o *options) {
+		o.middlewares = append(append([]func(*logrus.Entry) *logrus.Entry(nil), o.middlewares...), mw)
+	})
+}
+
+func derefFields(fields *Fields) Fields {
+	if fields == nil {
 		return nil
 	}
 
-	fnName := runtime.FuncForPC(pc).Name()
-	if lastSlash := strings.LastIndex(file, "/"); lastSlash >= 0 {
-		file = file[lastSlash+1:]
+	return *fields
+}
+
+// getCaller returns the caller fields for a line at level logged by the
+// function calling getCaller: none if level is excluded by SetCallerLevels,
+// and for Error and Fatal also the "callers" stack when SetErrorCallerFrames
+// is enabled.
+func getCaller(level logrus.Level) *logrus.Fields {
+	return callerFieldsAt(level, 2)
+}
+
+// callerFieldsAt is getCaller for the frame skip levels above the function
+// calling callerFieldsAt.
+func callerFieldsAt(level logrus.Level, skip int) *logrus.Fields {
+	o := loadOptions()
+	if o.callerLevels != nil && !o.callerLevels[level] {
+		return &Fields{}
 	}
 
-	fields := logrus.Fields{
-		"file": fmt.Sprintf("%s:%d", file, line),
-		"func": fnName,
+	fields := callerAt(skip + 2)
+	if n := o.errorCallerFrames; n > 0 && level <= logrus.ErrorLevel {
+		withCallers := make(Fields, len(*fields)+1)
+		for k, v := range *fields {
+			withCallers[k] = v
+		}
+		withCallers["callers"] = stackFrames(skip+2, n)
+		fields = &withCallers
+	}
+
+	return fields
+}
+
+// callerAt returns the file/func fields for the frame skip levels above
+// callerAt itself.
+func callerAt(skip int) *logrus.Fields {
+	o := loadOptions()
+	if o.customCaller != nil {
+		fields := o.customCaller(skip + 1)
+		return &fields
+	}
+	if !o.callerFile && !o.callerFunc {
+		return &Fields{}
+	}
+
+	pc, file, line, ok := runtimeCaller(skip)
+	if !ok {
+		return &Fields{}
+	}
+
+	fields := logrus.Fields{}
+	if o.callerFile {
+		if lastSlash := strings.LastIndex(file, "/"); lastSlash >= 0 {
+			file = file[lastSlash+1:]
+		}
+		fields["file"] = fmt.Sprintf("%s:%d", file, line)
+	}
+	if o.callerFunc {
+		fields["func"] = runtime.FuncForPC(pc).Name()
 	}
 
 	return &fields
 }
 
+// stackFrames returns up to n "file:line:func" frames starting skip levels
+// above stackFrames itself.
+func stackFrames(skip, n int) []string {
+	pcs := make([]uintptr, n)
+	pcs = pcs[:runtime.Callers(skip+1, pcs)]
+
+	frames := runtime.CallersFrames(pcs)
+	out := make([]string, 0, len(pcs))
+	for {
+		frame, more := frames.Next()
+		file := frame.File
+		if lastSlash := strings.LastIndex(file, "/"); lastSlash >= 0 {
+			file = file[lastSlash+1:]
+		}
+		out = append(out, fmt.Sprintf("%s:%d:%s", file, frame.Line, frame.Function))
+		if !more {
+			break
+		}
+	}
+
+	return out
+}
+
+// mergeErrorFields returns fields extended with what can be derived from
+// err: its own structured fields, whether it is retryable (via Retryable or
+// Temporary) and, if enabled, its type. Explicit
+// per-call fields win on collision.
+func mergeErrorFields(err error, fields *Fields) *Fields {
+	if err == nil {
+		return fields
+	}
+
+	merged := Fields{}
+	var fe fielder
+	if errors.As(err, &fe) {
+		for k, v := range fe.Fields() {
+			merged[k] = v
+		}
+	}
+	var re retryable
+	var te temporary
+	switch {
+	case errors.As(err, &re):
+		merged["retryable"] = re.Retryable()
+	case errors.As(err, &te):
+		merged["retryable"] = te.Temporary()
+	}
+	if loadOptions().logErrorType {
+		merged["error_type"] = fmt.Sprintf("%T", err)
+		root := err
+		for next := errors.Unwrap(root); next != nil; next = errors.Unwrap(root) {
+			root = next
+		}
+		if root != err {
+			merged["error_root_type"] = fmt.Sprintf("%T", root)
+		}
+	}
+	if len(merged) == 0 {
+		return fields
+	}
+
+	if fields != nil {
+		for k, v := range *fields {
+			merged[k] = v
+		}
+	}
+
+	return &merged
+}
+
+// enabled reports whether a line at level should be written for ctx. Log
+// functions check it before looking up the caller or building fields so
+// suppressed lines stay cheap. It must be called directly from the public
+// log function; others resolve their call site and use enabledAt.
+func enabled(ctx context.Context, level logrus.Level, msg string) bool {
+	return enabledAt(ctx, callerPC(2), level, msg)
+}
+
+// enabledAt is enabled for a log call made from the call site pc.
+func enabledAt(ctx context.Context, pc uintptr, level logrus.Level, msg string) bool {
+	return levelFor(ctx, pc) >= level && sampleAllow(ctx, level, msg)
+}
+
 func Info(ctx context.Context, msg string, fields *Fields) {
-	callerFields := getCaller()
+	if !enabled(ctx, logrus.InfoLevel, msg) {
+		return
+	}
+	callerFields := getCaller(logrus.InfoLevel)
 	generateLogger(ctx, fields).WithFields(*callerFields).Info(msg)
 }
 
 func Error(ctx context.Context, msg string, err error, fields *Fields) {
-	callerFields := getCaller()
-	generateLogger(ctx, fields).WithFields(*callerFields).WithError(err).Error(msg)
+	if !enabled(ctx, logrus.ErrorLevel, msg) {
+		return
+	}
+	callerFields := getCaller(logrus.ErrorLevel)
+	logError(generateLogger(ctx, mergeErrorFields(err, fields)).WithFields(*callerFields), msg, err)
+}
+
+func logError(entry *logrus.Entry, msg string, err error) {
+	switch {
+	case err != nil:
+		entry.WithError(err).Error(msg)
+	case loadOptions().nilError == NilErrorDowngradeToWarn:
+		entry.Warn(msg)
+		return
+	default:
+		entry.WithField("missing_error", true).Error(msg)
+	}
+
+	errorLogged(logrus.ErrorLevel)
 }
 
 func Debug(ctx context.Context, msg string, fields *Fields) {
-	callerFields := getCaller()
+	if !enabled(ctx, logrus.DebugLevel, msg) {
+		return
+	}
+	callerFields := getCaller(logrus.DebugLevel)
 	generateLogger(ctx, fields).WithFields(*callerFields).Debug(msg)
 }
 
 func Warn(ctx context.Context, msg string, fields *Fields) {
-	callerFields := getCaller()
+	if !enabled(ctx, logrus.WarnLevel, msg) {
+		return
+	}
+	callerFields := getCaller(logrus.WarnLevel)
 	generateLogger(ctx, fields).WithFields(*callerFields).Warn(msg)
 }
 
 func Fatal(ctx context.Context, msg string, fields *Fields) {
-	callerFields := getCaller()
-	generateLogger(ctx, fields).WithFields(*callerFields).Fatal(msg)
+	callerFields := getCaller(logrus.FatalLevel)
+	logFatal(generateLogger(ctx, fields).WithFields(*callerFields), msg)
+}
+
+func logFatal(entry *logrus.Entry, msg string) {
+	if loadOptions().fatalPanics {
+		entry.Log(logrus.FatalLevel, msg)
+		errorLogged(logrus.FatalLevel)
+		crashDump()
+		panic(msg)
+	}
+
+	entry.Log(logrus.FatalLevel, msg)
+	errorLogged(logrus.FatalLevel)
+	crashDump()
+	entry.Logger.Exit(1)
+}
+
+func Log(ctx context.Context, level logrus.Level, msg string, fields *Fields) {
+	if level != logrus.FatalLevel && !enabled(ctx, level, msg) {
+		return
+	}
+	callerFields := getCaller(level)
+	entry := generateLogger(ctx, fields).WithFields(*callerFields)
+	if level == logrus.FatalLevel {
+		logFatal(entry, msg)
+		return
+	}
+	if level == logrus.PanicLevel {
+		defer crashDump()
+	}
+
+	entry.Log(level, msg)
+	if level == logrus.ErrorLevel {
+		errorLogged(level)
+	}
+}
+
+// LogDepth is Log for helpers and extension packages that log on behalf of
+// their caller: the caller fields and the package matched by SetPackageLevel
+// are taken from depth frames above the function calling LogDepth, so
+// LogDepth(ctx, 0, ...) is the same as Log.
+func LogDepth(ctx context.Context, depth int, level logrus.Level, msg string, fields *Fields) {
+	if level != logrus.FatalLevel && !enabledAt(ctx, callerPC(depth+1), level, msg) {
+		return
+	}
+	callerFields := callerFieldsAt(level, depth+1)
+	entry := generateLogger(ctx, fields).WithFields(*callerFields)
+	if level == logrus.FatalLevel {
+		logFatal(entry, msg)
+		return
+	}
+	if level == logrus.PanicLevel {
+		defer crashDump()
+	}
+
+	entry.Log(level, msg)
+	if level == logrus.ErrorLevel {
+		errorLogged(level)
+	}
+}
+
+// LogAt is like Log but stamps the entry with t instead of the current time,
+// e.g. when replaying historical events.
+func LogAt(ctx context.Context, t time.Time, level logrus.Level, msg string, fields *Fields) {
+	if level != logrus.FatalLevel && !enabled(ctx, level, msg) {
+		return
+	}
+	callerFields := getCaller(level)
+	entry := generateLogger(ctx, fields).WithFields(*callerFields).WithTime(t)
+	if level == logrus.FatalLevel {
+		logFatal(entry, msg)
+		return
+	}
+	if level == logrus.PanicLevel {
+		defer crashDump()
+	}
+
+	entry.Log(level, msg)
+	if level == logrus.ErrorLevel {
+		errorLogged(level)
+	}
+}
+
+// TraceFunc logs "entering <name>" at Debug and returns a func that logs
+// "exiting <name>" with the elapsed "duration_ms", typically deferred.
+func TraceFunc(ctx context.Context, name string) func() {
+	if levelFor(ctx, callerPC(1)) < logrus.DebugLevel {
+		return func() {}
+	}
+
+	return traceFunc(ctx, name, getCaller(logrus.DebugLevel))
+}
+
+// Deprecated: Use TraceFunc.
+func Trace2(ctx context.Context, name string) func() {
+	deprecatedOnce("Trace2")
+	if levelFor(ctx, callerPC(1)) < logrus.DebugLevel {
+		return func() {}
+	}
+
+	return traceFunc(ctx, name, getCaller(logrus.DebugLevel))
+}
+
+func traceFunc(ctx context.Context, name string, callerFields *Fields) func() {
+	start := time.Now()
+	generateLogger(ctx, nil).WithFields(*callerFields).Debug("entering " + name)
+
+	return func() {
+		fields := Fields{"duration_ms": time.Since(start).Milliseconds()}
+		generateLogger(ctx, &fields).WithFields(*callerFields).Debug("exiting " + name)
+	}
 }
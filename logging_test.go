@@ -5,9 +5,14 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
@@ -20,7 +25,7 @@ func resetOnce() {
 // captureOutput redirects the logger output to a buffer and returns it.
 func captureOutput() *bytes.Buffer {
 	buf := &bytes.Buffer{}
-	log.SetOutput(buf)
+	SetOutput(buf)
 	log.SetFormatter(&logrus.JSONFormatter{})
 	return buf
 }
@@ -37,8 +42,8 @@ func TestSetup_DevelopmentMode(t *testing.T) {
 
 	Setup("debug", false)
 
-	if log.GetLevel() != logrus.DebugLevel {
-		t.Errorf("expected level %v, got %v", logrus.DebugLevel, log.GetLevel())
+	if currentLevel() != logrus.DebugLevel {
+		t.Errorf("expected level %v, got %v", logrus.DebugLevel, currentLevel())
 	}
 
 	if _, ok := log.Formatter.(*logrus.TextFormatter); !ok {
@@ -52,8 +57,8 @@ func TestSetup_ProductionMode(t *testing.T) {
 
 	Setup("warn", true)
 
-	if log.GetLevel() != logrus.WarnLevel {
-		t.Errorf("expected level %v, got %v", logrus.WarnLevel, log.GetLevel())
+	if currentLevel() != logrus.WarnLevel {
+		t.Errorf("expected level %v, got %v", logrus.WarnLevel, currentLevel())
 	}
 
 	if _, ok := log.Formatter.(*logrus.JSONFormatter); !ok {
@@ -67,8 +72,8 @@ func TestSetup_InvalidLevel_DefaultsToInfo(t *testing.T) {
 
 	Setup("notavalidlevel", true)
 
-	if log.GetLevel() != logrus.InfoLevel {
-		t.Errorf("expected InfoLevel as default, got %v", log.GetLevel())
+	if currentLevel() != logrus.InfoLevel {
+		t.Errorf("expected InfoLevel as default, got %v", currentLevel())
 	}
 }
 
@@ -79,15 +84,57 @@ func TestSetup_OnlyAppliesOnce(t *testing.T) {
 	Setup("debug", false)
 	Setup("error", true) // second call must be ignored
 
-	if log.GetLevel() != logrus.DebugLevel {
-		t.Errorf("second Setup call must be a no-op; expected DebugLevel, got %v", log.GetLevel())
+	if currentLevel() != logrus.DebugLevel {
+		t.Errorf("second Setup call must be a no-op; expected DebugLevel, got %v", currentLevel())
+	}
+}
+
+func TestSetupE_InvalidLevel_ReturnsError(t *testing.T) {
+	resetOnce()
+	defer resetOnce()
+	defer setLevel(logrus.InfoLevel)
+	defer log.SetFormatter(&logrus.JSONFormatter{})
+
+	setLevel(logrus.ErrorLevel)
+	log.SetFormatter(&logrus.JSONFormatter{})
+	if err := SetupE("bogus", false); err == nil {
+		t.Fatal("expected an error for an invalid level")
+	}
+
+	if currentLevel() != logrus.ErrorLevel {
+		t.Errorf("expected level to stay %v, got %v", logrus.ErrorLevel, currentLevel())
+	}
+	if _, ok := log.Formatter.(*logrus.TextFormatter); ok {
+		t.Error("expected formatter to be left untouched")
+	}
+
+	if err := SetupE("debug", true); err != nil {
+		t.Fatalf("expected a valid level to be accepted after a rejected call, got %v", err)
+	}
+	if currentLevel() != logrus.DebugLevel {
+		t.Errorf("expected level %v, got %v", logrus.DebugLevel, currentLevel())
+	}
+}
+
+func TestGetLevel_RoundTripsSetupLevel(t *testing.T) {
+	resetOnce()
+	defer resetOnce()
+
+	Setup("warn", true)
+
+	got := GetLevel()
+	if got != "warning" {
+		t.Errorf("expected level 'warning', got %q", got)
+	}
+	if lvl, err := logrus.ParseLevel(got); err != nil || lvl != logrus.WarnLevel {
+		t.Errorf("expected %q to parse back to WarnLevel, got %v (err %v)", got, lvl, err)
 	}
 }
 
 func TestInfo(t *testing.T) {
 	buf := captureOutput()
 	defer restoreOutput()
-	log.SetLevel(logrus.InfoLevel)
+	setLevel(logrus.InfoLevel)
 
 	ctx := context.Background()
 	fields := Fields{"key": "value"}
@@ -118,7 +165,7 @@ func TestInfo(t *testing.T) {
 func TestError(t *testing.T) {
 	buf := captureOutput()
 	defer restoreOutput()
-	log.SetLevel(logrus.ErrorLevel)
+	setLevel(logrus.ErrorLevel)
 
 	ctx := context.Background()
 	fields := Fields{"request_id": "abc-123"}
@@ -147,7 +194,7 @@ func TestError(t *testing.T) {
 func TestDebug(t *testing.T) {
 	buf := captureOutput()
 	defer restoreOutput()
-	log.SetLevel(logrus.DebugLevel)
+	setLevel(logrus.DebugLevel)
 
 	ctx := context.Background()
 	fields := Fields{"component": "worker"}
@@ -169,7 +216,7 @@ func TestDebug(t *testing.T) {
 func TestWarn(t *testing.T) {
 	buf := captureOutput()
 	defer restoreOutput()
-	log.SetLevel(logrus.WarnLevel)
+	setLevel(logrus.WarnLevel)
 
 	ctx := context.Background()
 	fields := Fields{"threshold": 90}
@@ -191,7 +238,7 @@ func TestWarn(t *testing.T) {
 func TestDebug_SuppressedWhenLevelIsInfo(t *testing.T) {
 	buf := captureOutput()
 	defer restoreOutput()
-	log.SetLevel(logrus.InfoLevel)
+	setLevel(logrus.InfoLevel)
 
 	ctx := context.Background()
 	fields := Fields{}
@@ -206,7 +253,7 @@ func TestGetCaller_ReturnsFileAndFunc(t *testing.T) {
 	// getCaller uses depth=2: direct call here simulates one extra frame.
 	// We call it indirectly through a wrapper to match production depth.
 	wrapper := func() *Fields {
-		return getCaller()
+		return getCaller(logrus.InfoLevel)
 	}
 	fields := wrapper()
 
@@ -240,3 +287,745 @@ func TestGenerateLogger_ContextPropagated(t *testing.T) {
 		t.Errorf("expected trace_id 'abc-xyz' in context, got %v", got)
 	}
 }
+
+type fieldsError struct {
+	msg    string
+	fields map[string]interface{}
+}
+
+func (e *fieldsError) Error() string                  { return e.msg }
+func (e *fieldsError) Fields() map[string]interface{} { return e.fields }
+
+func TestError_MergesFieldsFromError(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.ErrorLevel)
+
+	ctx := context.Background()
+	fields := Fields{"order_id": "explicit"}
+	fieldsErr := &fieldsError{
+		msg:    "payment declined",
+		fields: map[string]interface{}{"order_id": "from-error", "customer_id": "cust-42"},
+	}
+	Error(ctx, "checkout failed", fmt.Errorf("checkout: %w", fieldsErr), &fields)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	if entry["customer_id"] != "cust-42" {
+		t.Errorf("expected customer_id from error fields, got %v", entry["customer_id"])
+	}
+	if entry["order_id"] != "explicit" {
+		t.Errorf("expected explicit order_id to win over error fields, got %v", entry["order_id"])
+	}
+	if fields["customer_id"] != nil {
+		t.Error("expected caller's fields map to be left untouched")
+	}
+}
+
+func benchmarkInfo(b *testing.B, fields *Fields) {
+	log.SetOutput(io.Discard)
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Info(ctx, "benchmark message", fields)
+	}
+}
+
+func BenchmarkInfo_NoFields(b *testing.B) {
+	benchmarkInfo(b, nil)
+}
+
+func BenchmarkInfo_EmptyFields(b *testing.B) {
+	benchmarkInfo(b, &Fields{})
+}
+
+func BenchmarkInfo_WithFields(b *testing.B) {
+	benchmarkInfo(b, &Fields{"key": "value"})
+}
+
+func TestInfo_CallerLookupFails_StillLogs(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	runtimeCaller = func(int) (uintptr, string, int, bool) { return 0, "", 0, false }
+	defer func() { runtimeCaller = runtime.Caller }()
+
+	Info(context.Background(), "no caller info", nil)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	if entry["msg"] != "no caller info" {
+		t.Errorf("expected msg 'no caller info', got %v", entry["msg"])
+	}
+	if _, ok := entry["file"]; ok {
+		t.Error("expected no 'file' field when caller lookup fails")
+	}
+}
+
+func TestTraceFunc_LogsEntryAndExit(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.DebugLevel)
+	defer setLevel(logrus.InfoLevel)
+
+	func() {
+		defer TraceFunc(context.Background(), "doWork")()
+		time.Sleep(5 * time.Millisecond)
+	}()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %s", len(lines), buf.String())
+	}
+
+	var enter, exit map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &enter); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &exit); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	if enter["msg"] != "entering doWork" {
+		t.Errorf("expected msg 'entering doWork', got %v", enter["msg"])
+	}
+	if _, ok := enter["duration_ms"]; ok {
+		t.Error("expected no duration_ms on the entry line")
+	}
+	if exit["msg"] != "exiting doWork" {
+		t.Errorf("expected msg 'exiting doWork', got %v", exit["msg"])
+	}
+	if d, ok := exit["duration_ms"].(float64); !ok || d < 5 {
+		t.Errorf("expected duration_ms >= 5 on the exit line, got %v", exit["duration_ms"])
+	}
+}
+
+func TestLazyFieldValue_OnlyEvaluatedWhenLogged(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	calls := 0
+	fields := Fields{"state": func() interface{} {
+		calls++
+		return "expensive"
+	}}
+
+	ctx := context.Background()
+	Debug(ctx, "suppressed", &fields)
+	if calls != 0 {
+		t.Fatalf("expected lazy field not to be evaluated for a suppressed line, got %d calls", calls)
+	}
+
+	Info(ctx, "written", &fields)
+	if calls != 1 {
+		t.Fatalf("expected lazy field to be evaluated once, got %d calls", calls)
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+	if entry["state"] != "expensive" {
+		t.Errorf("expected state 'expensive', got %v", entry["state"])
+	}
+}
+
+func TestError_NilErrorOmitsErrorField(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	Error(context.Background(), "nil error", nil, nil)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	if entry["level"] != "error" {
+		t.Errorf("expected level 'error', got %v", entry["level"])
+	}
+	if _, ok := entry["error"]; ok {
+		t.Errorf("expected no error field for a nil error, got %v", entry["error"])
+	}
+	if entry["missing_error"] != true {
+		t.Errorf("expected missing_error marker for a nil error, got %v", entry["missing_error"])
+	}
+}
+
+func TestError_RealErrorHasNoMissingMarker(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	Error(context.Background(), "real error", errors.New("boom"), nil)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	if entry["error"] != "boom" {
+		t.Errorf("expected error 'boom', got %v", entry["error"])
+	}
+	if _, ok := entry["missing_error"]; ok {
+		t.Error("expected no missing_error marker for a real error")
+	}
+}
+
+func TestError_NilErrorDowngradeToWarn(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	SetNilErrorPolicy(NilErrorDowngradeToWarn)
+	defer SetNilErrorPolicy(NilErrorOmitField)
+
+	Error(context.Background(), "nil error", nil, nil)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	if entry["level"] != "warning" {
+		t.Errorf("expected level 'warning', got %v", entry["level"])
+	}
+	if _, ok := entry["error"]; ok {
+		t.Errorf("expected no error field for a nil error, got %v", entry["error"])
+	}
+}
+
+func TestLog_UsesGivenLevel(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	tests := []struct {
+		level   logrus.Level
+		want    string
+		emitted bool
+	}{
+		{logrus.ErrorLevel, "error", true},
+		{logrus.WarnLevel, "warning", true},
+		{logrus.InfoLevel, "info", true},
+		{logrus.DebugLevel, "debug", false},
+		{logrus.TraceLevel, "trace", false},
+	}
+
+	ctx := context.Background()
+	for _, tt := range tests {
+		buf.Reset()
+		fields := Fields{"status": 503}
+		Log(ctx, tt.level, "dynamic level", &fields)
+
+		if !tt.emitted {
+			if buf.Len() != 0 {
+				t.Errorf("expected %s line to be suppressed, got: %s", tt.want, buf.String())
+			}
+			continue
+		}
+
+		var entry map[string]interface{}
+		if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+			t.Fatalf("expected valid JSON output for %s: %v", tt.want, err)
+		}
+		if entry["level"] != tt.want {
+			t.Errorf("expected level %q, got %v", tt.want, entry["level"])
+		}
+		if entry["status"] != float64(503) {
+			t.Errorf("expected status field, got %v", entry["status"])
+		}
+		if file, _ := entry["file"].(string); !strings.HasPrefix(file, "logging_test.go:") {
+			t.Errorf("expected caller file to be the test, got %v", entry["file"])
+		}
+	}
+}
+
+func TestSetCallerFields_Combinations(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+	defer SetCallerFields(true, true)
+
+	calls := 0
+	runtimeCaller = func(skip int) (uintptr, string, int, bool) {
+		calls++
+		return runtime.Caller(skip + 1)
+	}
+	defer func() { runtimeCaller = runtime.Caller }()
+
+	tests := []struct {
+		file, fn bool
+	}{
+		{true, true},
+		{true, false},
+		{false, true},
+		{false, false},
+	}
+
+	for _, tt := range tests {
+		buf.Reset()
+		calls = 0
+		SetCallerFields(tt.file, tt.fn)
+
+		Info(context.Background(), "caller fields", nil)
+
+		var entry map[string]interface{}
+		if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+			t.Fatalf("expected valid JSON output: %v", err)
+		}
+
+		if _, ok := entry["file"]; ok != tt.file {
+			t.Errorf("file=%v func=%v: expected file present=%v, got %v", tt.file, tt.fn, tt.file, ok)
+		}
+		if _, ok := entry["func"]; ok != tt.fn {
+			t.Errorf("file=%v func=%v: expected func present=%v, got %v", tt.file, tt.fn, tt.fn, ok)
+		}
+		if wantCalls := map[bool]int{true: 1, false: 0}[tt.file || tt.fn]; calls != wantCalls {
+			t.Errorf("file=%v func=%v: expected %d caller lookups, got %d", tt.file, tt.fn, wantCalls, calls)
+		}
+	}
+}
+
+func TestFatal_PanicsWhenFatalPanicsEnabled(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	SetFatalPanics(true)
+	defer SetFatalPanics(false)
+
+	exited := false
+	log.ExitFunc = func(int) { exited = true }
+	defer func() { log.ExitFunc = nil }()
+
+	recovered := func() (r interface{}) {
+		defer func() { r = recover() }()
+		Fatal(context.Background(), "cannot continue", nil)
+		return nil
+	}()
+
+	if recovered != "cannot continue" {
+		t.Errorf("expected panic with the message, got %v", recovered)
+	}
+	if exited {
+		t.Error("expected exit func not to be called")
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+	if entry["level"] != "fatal" || entry["msg"] != "cannot continue" {
+		t.Errorf("expected fatal line to be logged before the panic, got %v", entry)
+	}
+}
+
+func TestLogAt_UsesGivenTimestamp(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	eventTime := time.Date(2024, 2, 29, 13, 45, 0, 0, time.UTC)
+	fields := Fields{"source": "backfill"}
+	LogAt(context.Background(), eventTime, logrus.WarnLevel, "replayed event", &fields)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	ts, _ := entry["time"].(string)
+	got, err := time.Parse(time.RFC3339, ts)
+	if err != nil || !got.Equal(eventTime) {
+		t.Errorf("expected time %v, got %q", eventTime, ts)
+	}
+	if entry["level"] != "warning" || entry["source"] != "backfill" {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+}
+
+func BenchmarkSuppressedDebug(b *testing.B) {
+	log.SetOutput(io.Discard)
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	calls := 0
+	runtimeCaller = func(skip int) (uintptr, string, int, bool) {
+		calls++
+		return runtime.Caller(skip + 1)
+	}
+	defer func() { runtimeCaller = runtime.Caller }()
+
+	ctx := context.Background()
+	fields := Fields{"key": "value"}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Debug(ctx, "suppressed", &fields)
+	}
+	b.StopTimer()
+
+	if calls != 0 {
+		b.Fatalf("expected no runtime.Caller lookups for suppressed Debug lines, got %d", calls)
+	}
+}
+
+type quotaError struct {
+	limit int
+}
+
+func (e *quotaError) Error() string { return fmt.Sprintf("quota of %d exceeded", e.limit) }
+
+func TestSetLogErrorType(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	SetLogErrorType(true)
+	defer SetLogErrorType(false)
+
+	ctx := context.Background()
+	Error(ctx, "direct", &quotaError{limit: 10}, nil)
+	Error(ctx, "wrapped", fmt.Errorf("upload: %w", &quotaError{limit: 10}), nil)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+
+	var direct, wrapped map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &direct); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &wrapped); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	if direct["error_type"] != "*logruswrapper.quotaError" {
+		t.Errorf("expected error_type '*logruswrapper.quotaError', got %v", direct["error_type"])
+	}
+	if _, ok := direct["error_root_type"]; ok {
+		t.Error("expected no error_root_type for an unwrapped error")
+	}
+	if wrapped["error_type"] != "*fmt.wrapError" {
+		t.Errorf("expected error_type '*fmt.wrapError', got %v", wrapped["error_type"])
+	}
+	if wrapped["error_root_type"] != "*logruswrapper.quotaError" {
+		t.Errorf("expected error_root_type '*logruswrapper.quotaError', got %v", wrapped["error_root_type"])
+	}
+}
+
+func TestSetErrorCallerFrames(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	SetErrorCallerFrames(2)
+	defer SetErrorCallerFrames(0)
+
+	ctx := context.Background()
+	Error(ctx, "failed", errors.New("boom"), nil)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	callers, ok := entry["callers"].([]interface{})
+	if !ok || len(callers) != 2 {
+		t.Fatalf("expected 2 caller frames, got %v", entry["callers"])
+	}
+	if first, _ := callers[0].(string); !strings.HasPrefix(first, "logging_test.go:") || !strings.HasSuffix(first, ".TestSetErrorCallerFrames") {
+		t.Errorf("expected first frame at the Error call site, got %v", callers[0])
+	}
+	if entry["file"] == nil || entry["func"] == nil {
+		t.Errorf("expected file and func fields to be kept, got %v", entry)
+	}
+
+	buf.Reset()
+	Info(ctx, "fine", nil)
+	entry = nil
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+	if _, ok := entry["callers"]; ok {
+		t.Errorf("expected no callers on Info lines, got %v", entry["callers"])
+	}
+}
+
+type temporaryError struct{ temporary bool }
+
+func (e temporaryError) Error() string   { return "connection reset" }
+func (e temporaryError) Temporary() bool { return e.temporary }
+
+func TestError_RetryableField(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		err  error
+		want interface{}
+	}{
+		{"temporary", fmt.Errorf("dial: %w", temporaryError{temporary: true}), true},
+		{"not temporary", temporaryError{temporary: false}, false},
+		{"permanent", errors.New("invalid input"), nil},
+	}
+
+	for _, tt := range tests {
+		buf.Reset()
+		Error(ctx, "failed", tt.err, nil)
+
+		var entry map[string]interface{}
+		if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+			t.Fatalf("%s: expected valid JSON output: %v", tt.name, err)
+		}
+		got, ok := entry["retryable"]
+		if tt.want == nil {
+			if ok {
+				t.Errorf("%s: expected no retryable field, got %v", tt.name, got)
+			}
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected retryable %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestSetCallerFunc_ReplacesDefaultFields(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	SetCallerFunc(func(skip int) Fields {
+		_, file, line, _ := runtime.Caller(skip)
+		return Fields{"source": fmt.Sprintf("%s:%d", filepath.Base(file), line)}
+	})
+	defer SetCallerFunc(nil)
+
+	Info(context.Background(), "custom caller", nil)
+	_, _, line, _ := runtime.Caller(0)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	if want := fmt.Sprintf("logging_test.go:%d", line-1); entry["source"] != want {
+		t.Errorf("expected source %q, got %v", want, entry["source"])
+	}
+	if _, ok := entry["file"]; ok {
+		t.Errorf("expected default file field to be replaced, got %v", entry["file"])
+	}
+	if _, ok := entry["func"]; ok {
+		t.Errorf("expected default func field to be replaced, got %v", entry["func"])
+	}
+}
+
+func TestUse_RunsMiddlewareInOrder(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+	defer updateOptions(func(o *options) { o.middlewares = nil })
+
+	Use(func(e *logrus.Entry) *logrus.Entry {
+		return e.WithFields(Fields{"region": "eu", "trail": "first"})
+	})
+	Use(func(e *logrus.Entry) *logrus.Entry {
+		trail, _ := e.Data["trail"].(string)
+		return e.WithField("trail", trail+",second")
+	})
+
+	Info(context.Background(), "enriched", nil)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+	if entry["region"] != "eu" {
+		t.Errorf("expected first middleware's field, got %v", entry["region"])
+	}
+	if entry["trail"] != "first,second" {
+		t.Errorf("expected middlewares to run in registration order, got %v", entry["trail"])
+	}
+}
+
+// TestReconfigure_ConcurrentWithLogging is meant to be run with -race.
+func TestReconfigure_ConcurrentWithLogging(t *testing.T) {
+	captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+	defer setLevel(logrus.InfoLevel)
+
+	ctx := context.Background()
+	scoped := WithLevel(ctx, logrus.DebugLevel)
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					Info(ctx, "concurrent", nil)
+					Debug(scoped, "scoped", nil)
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 100; i++ {
+		level := "info"
+		if i%2 == 0 {
+			level = "debug"
+		}
+		if err := Reconfigure(level); err != nil {
+			t.Fatal(err)
+		}
+		SetCompactFormat(i%2 == 0)
+		SetOutput(&lockedBuffer{})
+	}
+	close(stop)
+	wg.Wait()
+	SetCompactFormat(false)
+}
+
+func TestSetCallerLevels_OnlyListedLevels(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	SetCallerLevels(logrus.ErrorLevel, logrus.WarnLevel)
+	defer SetCallerLevels()
+
+	calls := 0
+	runtimeCaller = func(skip int) (uintptr, string, int, bool) {
+		calls++
+		return runtime.Caller(skip + 1)
+	}
+	defer func() { runtimeCaller = runtime.Caller }()
+
+	ctx := context.Background()
+	Info(ctx, "no caller", nil)
+	var info map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &info); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+	if _, ok := info["file"]; ok {
+		t.Errorf("expected no caller fields on Info, got %v", info["file"])
+	}
+	if calls != 0 {
+		t.Errorf("expected Info to skip the caller lookup, got %d calls", calls)
+	}
+
+	buf.Reset()
+	Error(ctx, "with caller", errors.New("boom"), nil)
+	var errEntry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &errEntry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+	if file, _ := errEntry["file"].(string); !strings.HasPrefix(file, "logging_test.go:") {
+		t.Errorf("expected caller fields on Error, got %v", errEntry["file"])
+	}
+	if errEntry["func"] == nil {
+		t.Error("expected func field on Error")
+	}
+}
+
+func TestGetLevel_ConcurrentWithReconfigure(t *testing.T) {
+	defer setLevel(logrus.InfoLevel)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			_ = Reconfigure("debug")
+			_ = Reconfigure("warn")
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			if lvl := GetLevel(); lvl != "debug" && lvl != "warning" && lvl != "info" {
+				t.Errorf("unexpected level %q", lvl)
+			}
+		}
+	}()
+	wg.Wait()
+}
+
+// logOnBehalf logs through LogDepth as an extension package would.
+func logOnBehalf(ctx context.Context, msg string) {
+	LogDepth(ctx, 1, logrus.InfoLevel, msg, nil)
+}
+
+func TestLogDepth_ReportsCallerOfHelper(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	logOnBehalf(context.Background(), "on behalf")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+	if fn, _ := entry["func"].(string); !strings.HasSuffix(fn, ".TestLogDepth_ReportsCallerOfHelper") {
+		t.Errorf("expected the helper's caller, got %v", entry["func"])
+	}
+}
+
+func TestSetLevel_ErrorSilencesBackgroundInfo(t *testing.T) {
+	captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	buf := &lockedBuffer{}
+	log.SetOutput(buf)
+
+	SetPerRequestSampling(sampleReqKey{}, 1)
+	defer SetPerRequestSampling(nil, 0)
+	req := context.WithValue(context.Background(), sampleReqKey{}, "req-quiet")
+	Info(req, "repeated", nil)
+	Info(req, "repeated", nil)
+	buf.mu.Lock()
+	buf.buf.Reset()
+	buf.mu.Unlock()
+
+	setLevel(logrus.ErrorLevel)
+
+	m := NewMeter("quiet")
+	m.Mark(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := StartRuntimeStatsLogger(ctx, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	<-done
+	m.Close()
+	FlushRequestSampling(req)
+
+	if out := buf.String(); out != "" {
+		t.Errorf("expected no Info lines at Error level, got %q", out)
+	}
+}
@@ -0,0 +1,15 @@
+module github.com/nandhasuhendra/logrus-wrapper/loggrpc
+
+go 1.25.0
+
+require (
+	github.com/nandhasuhendra/logrus-wrapper v0.1.0
+	github.com/sirupsen/logrus v1.9.4
+	google.golang.org/grpc v1.84.0
+)
+
+require (
+	golang.org/x/sys v0.47.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20260706201446-f0a921348800 // indirect
+	google.golang.org/protobuf v1.36.12 // indirect
+)
@@ -0,0 +1,43 @@
+// Package loggrpc logs gRPC status errors through logruswrapper. It is a
+// separate module so that only programs using it depend on gRPC.
+package loggrpc
+
+import (
+	"context"
+
+	logruswrapper "github.com/nandhasuhendra/logrus-wrapper"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// LogStatus logs msg with a "grpc_code" field and returns
+// status.Error(code, msg), so handlers can log and return in one step. The
+// level follows the code: Info for OK and Canceled, Warn for errors caused
+// by the client, Error for server-side failures.
+func LogStatus(ctx context.Context, code codes.Code, msg string, fields *logruswrapper.Fields) error {
+	statusFields := logruswrapper.Fields{}
+	if fields != nil {
+		for k, v := range *fields {
+			statusFields[k] = v
+		}
+	}
+	statusFields["grpc_code"] = code.String()
+
+	logruswrapper.LogDepth(ctx, 1, codeLevel(code), msg, &statusFields)
+
+	return status.Error(code, msg)
+}
+
+func codeLevel(code codes.Code) logrus.Level {
+	switch code {
+	case codes.OK, codes.Canceled:
+		return logrus.InfoLevel
+	case codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.PermissionDenied,
+		codes.Unauthenticated, codes.FailedPrecondition, codes.OutOfRange, codes.ResourceExhausted,
+		codes.Aborted:
+		return logrus.WarnLevel
+	}
+
+	return logrus.ErrorLevel
+}
@@ -0,0 +1,67 @@
+package loggrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	logruswrapper "github.com/nandhasuhendra/logrus-wrapper"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLogStatus_LevelAndReturnedError(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logruswrapper.SetOutput(buf)
+	defer logruswrapper.SetOutput(os.Stdout)
+
+	tests := []struct {
+		code  codes.Code
+		level string
+	}{
+		{codes.NotFound, "warning"},
+		{codes.InvalidArgument, "warning"},
+		{codes.Internal, "error"},
+		{codes.Unknown, "error"},
+	}
+
+	for _, tt := range tests {
+		buf.Reset()
+		err := LogStatus(context.Background(), tt.code, "lookup failed", &logruswrapper.Fields{"id": "42"})
+
+		if status.Code(err) != tt.code {
+			t.Errorf("%s: expected returned code %s, got %s", tt.code, tt.code, status.Code(err))
+		}
+
+		var entry map[string]interface{}
+		if jsonErr := json.Unmarshal(buf.Bytes(), &entry); jsonErr != nil {
+			t.Fatalf("%s: expected valid JSON output: %v", tt.code, jsonErr)
+		}
+		if entry["level"] != tt.level {
+			t.Errorf("%s: expected level %q, got %v", tt.code, tt.level, entry["level"])
+		}
+		if entry["grpc_code"] != tt.code.String() || entry["id"] != "42" {
+			t.Errorf("%s: expected grpc_code and id fields, got %v", tt.code, entry)
+		}
+	}
+}
+
+func TestLogStatus_OK(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logruswrapper.SetOutput(buf)
+	defer logruswrapper.SetOutput(os.Stdout)
+
+	if err := LogStatus(context.Background(), codes.OK, "done", nil); err != nil {
+		t.Errorf("expected nil error for OK, got %v", err)
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+	if entry["level"] != "info" || entry["grpc_code"] != "OK" {
+		t.Errorf("expected info line with grpc_code OK, got %v", entry)
+	}
+}
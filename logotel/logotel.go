@@ -0,0 +1,79 @@
+// Package logotel connects logruswrapper to OpenTelemetry tracing. It is a
+// separate module so that only programs using it depend on OpenTelemetry.
+package logotel
+
+import (
+	"fmt"
+	"sync"
+
+	logruswrapper "github.com/nandhasuhendra/logrus-wrapper"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	hookMu    sync.Mutex
+	hookAdded bool
+	hook      = spanEventHook{}
+)
+
+// SetTraceEventInjection also records Error and Warn lines as events on the
+// recording OpenTelemetry span in the entry's context, named after the
+// message and carrying the level and fields as attributes.
+func SetTraceEventInjection(enabled bool) {
+	hookMu.Lock()
+	defer hookMu.Unlock()
+
+	switch {
+	case enabled && !hookAdded:
+		logruswrapper.AddHook(hook)
+	case !enabled && hookAdded:
+		logruswrapper.RemoveHook(hook)
+	}
+	hookAdded = enabled
+}
+
+type spanEventHook struct{}
+
+func (spanEventHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel, logrus.WarnLevel}
+}
+
+func (spanEventHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+	span := trace.SpanFromContext(entry.Context)
+	if !span.IsRecording() {
+		return nil
+	}
+
+	attrs := make([]attribute.KeyValue, 0, len(entry.Data)+1)
+	attrs = append(attrs, attribute.String("level", entry.Level.String()))
+	for k, v := range entry.Data {
+		attrs = append(attrs, spanAttribute(k, v))
+	}
+	span.AddEvent(entry.Message, trace.WithAttributes(attrs...))
+
+	return nil
+}
+
+func spanAttribute(key string, v interface{}) attribute.KeyValue {
+	switch v := v.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case float64:
+		return attribute.Float64(key, v)
+	case error:
+		return attribute.String(key, v.Error())
+	}
+
+	return attribute.String(key, fmt.Sprint(v))
+}
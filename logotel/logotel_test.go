@@ -0,0 +1,69 @@
+package logotel
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	logruswrapper "github.com/nandhasuhendra/logrus-wrapper"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSetTraceEventInjection_AddsSpanEvent(t *testing.T) {
+	logruswrapper.SetOutput(io.Discard)
+	defer logruswrapper.SetOutput(os.Stdout)
+
+	SetTraceEventInjection(true)
+	defer SetTraceEventInjection(false)
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, span := provider.Tracer("test").Start(context.Background(), "checkout")
+
+	logruswrapper.Info(ctx, "not recorded", nil)
+	logruswrapper.Error(ctx, "payment failed", errors.New("declined"), &logruswrapper.Fields{"order_id": "o-1", "attempt": 2})
+	span.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 ended span, got %d", len(spans))
+	}
+	events := spans[0].Events()
+	if len(events) != 1 {
+		t.Fatalf("expected 1 span event, got %d", len(events))
+	}
+	if events[0].Name != "payment failed" {
+		t.Errorf("expected event named after the message, got %q", events[0].Name)
+	}
+
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range events[0].Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	if attrs["level"].AsString() != "error" || attrs["error"].AsString() != "declined" {
+		t.Errorf("expected level and error attributes, got %v", events[0].Attributes)
+	}
+	if attrs["order_id"].AsString() != "o-1" || attrs["attempt"].AsInt64() != 2 {
+		t.Errorf("expected field attributes, got %v", events[0].Attributes)
+	}
+}
+
+func TestSetTraceEventInjection_Disabled(t *testing.T) {
+	logruswrapper.SetOutput(io.Discard)
+	defer logruswrapper.SetOutput(os.Stdout)
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, span := provider.Tracer("test").Start(context.Background(), "checkout")
+
+	logruswrapper.Warn(ctx, "slow", nil)
+	span.End()
+
+	if events := recorder.Ended()[0].Events(); len(events) != 0 {
+		t.Errorf("expected no span events when injection is disabled, got %v", events)
+	}
+}
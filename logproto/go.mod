@@ -0,0 +1,11 @@
+module github.com/nandhasuhendra/logrus-wrapper/logproto
+
+go 1.25.0
+
+require (
+	github.com/nandhasuhendra/logrus-wrapper v0.1.0
+	github.com/sirupsen/logrus v1.9.4
+	google.golang.org/protobuf v1.36.12
+)
+
+require golang.org/x/sys v0.13.0 // indirect
@@ -0,0 +1,51 @@
+// Package logproto logs protobuf messages through logruswrapper. It is a
+// separate module so that only programs using it depend on protobuf.
+package logproto
+
+import (
+	"context"
+	"encoding/json"
+
+	logruswrapper "github.com/nandhasuhendra/logrus-wrapper"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// marshal is protojson.Marshal, replaced in tests.
+var marshal = protojson.Marshal
+
+// Info logs m at Info under a "payload" field, rendered with its protojson
+// field names rather than the Go struct layout, or holding the encoding
+// error if m cannot be rendered. m is only encoded when the line is written.
+func Info(ctx context.Context, msg string, m proto.Message) {
+	fields := logruswrapper.Fields{
+		"payload": func() interface{} {
+			payload, err := protoPayload(m)
+			if err != nil {
+				return err
+			}
+			return payload
+		},
+	}
+
+	logruswrapper.LogDepth(ctx, 1, logrus.InfoLevel, msg, &fields)
+}
+
+func protoPayload(m proto.Message) (map[string]interface{}, error) {
+	if m == nil || !m.ProtoReflect().IsValid() {
+		return nil, nil
+	}
+
+	b, err := marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(b, &payload); err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
@@ -0,0 +1,89 @@
+package logproto
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	logruswrapper "github.com/nandhasuhendra/logrus-wrapper"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/apipb"
+)
+
+func captureOutput(t *testing.T) *bytes.Buffer {
+	buf := &bytes.Buffer{}
+	logruswrapper.SetOutput(buf)
+	t.Cleanup(func() { logruswrapper.SetOutput(os.Stdout) })
+
+	return buf
+}
+
+func TestInfo_LogsPayloadWithProtoJSONNames(t *testing.T) {
+	buf := captureOutput(t)
+
+	Info(context.Background(), "rpc registered", &apipb.Method{
+		Name:            "GetOrder",
+		RequestTypeUrl:  "type.googleapis.com/shop.GetOrderRequest",
+		ResponseTypeUrl: "type.googleapis.com/shop.Order",
+	})
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	payload, ok := entry["payload"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected structured payload field, got %v", entry["payload"])
+	}
+	if payload["name"] != "GetOrder" {
+		t.Errorf("expected payload name 'GetOrder', got %v", payload["name"])
+	}
+	if payload["requestTypeUrl"] != "type.googleapis.com/shop.GetOrderRequest" {
+		t.Errorf("expected protojson name requestTypeUrl, got %v", payload)
+	}
+	if _, ok := payload["RequestTypeUrl"]; ok {
+		t.Error("expected Go field names not to be used")
+	}
+	if fn, _ := entry["func"].(string); !strings.HasSuffix(fn, ".TestInfo_LogsPayloadWithProtoJSONNames") {
+		t.Errorf("expected the caller of Info in the func field, got %v", entry["func"])
+	}
+}
+
+func TestInfo_NilMessage(t *testing.T) {
+	buf := captureOutput(t)
+
+	var m *apipb.Method
+	Info(context.Background(), "nil message", m)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+	if entry["msg"] != "nil message" {
+		t.Errorf("expected the line to be logged, got %v", entry)
+	}
+}
+
+func TestInfo_DisabledSkipsEncoding(t *testing.T) {
+	buf := captureOutput(t)
+
+	calls := 0
+	marshal = func(m proto.Message) ([]byte, error) {
+		calls++
+		return protojson.Marshal(m)
+	}
+	defer func() { marshal = protojson.Marshal }()
+
+	ctx := logruswrapper.WithLevel(context.Background(), logrus.WarnLevel)
+	Info(ctx, "suppressed", &apipb.Method{Name: "GetOrder"})
+
+	if calls != 0 || buf.Len() != 0 {
+		t.Errorf("expected no encoding or output below the level, got %d calls and %q", calls, buf.String())
+	}
+}
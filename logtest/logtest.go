@@ -0,0 +1,158 @@
+// Package logtest collects the entries written through logruswrapper so
+// tests can assert on them:
+//
+//	c := logtest.Collect(t)
+//	doWork(ctx)
+//	entries := c.Drain(ctx)
+//
+// Nothing is collected until Collect is called, and collection stops when
+// the test finishes. SetFailOnError and ExpectNoWarnings fail a test on
+// unexpected Error or Warn lines.
+package logtest
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	logruswrapper "github.com/nandhasuhendra/logrus-wrapper"
+	"github.com/sirupsen/logrus"
+)
+
+// collectLimit bounds how many entries a Collector keeps between drains;
+// the oldest are dropped first.
+const collectLimit = 10000
+
+// Collector records every entry written while it is registered.
+type Collector struct {
+	mu      sync.Mutex
+	entries []*logrus.Entry
+}
+
+// Collect starts recording entries until t finishes.
+func Collect(t testing.TB) *Collector {
+	c := &Collector{}
+	logruswrapper.AddHook(c)
+	t.Cleanup(func() {
+		logruswrapper.RemoveHook(c)
+	})
+
+	return c
+}
+
+func (c *Collector) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (c *Collector) Fire(entry *logrus.Entry) error {
+	e := entry.Dup()
+	e.Level = entry.Level
+	e.Message = entry.Message
+	e.Caller = entry.Caller
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) == collectLimit {
+		c.entries = c.entries[1:]
+	}
+	c.entries = append(c.entries, e)
+
+	return nil
+}
+
+// Drain flushes the async queue and returns, in order, every entry logged
+// since Collect or the previous Drain. If ctx ends before the queue is
+// flushed, the entries collected so far are returned.
+func (c *Collector) Drain(ctx context.Context) []*logrus.Entry {
+	flushed := make(chan struct{})
+	go func() {
+		logruswrapper.Flush()
+		close(flushed)
+	}()
+
+	select {
+	case <-flushed:
+	case <-ctx.Done():
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries := c.entries
+	c.entries = nil
+
+	return entries
+}
+
+type failOnErrorHook struct {
+	t testing.TB
+}
+
+func (h *failOnErrorHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel}
+}
+
+func (h *failOnErrorHook) Fire(entry *logrus.Entry) error {
+	h.t.Helper()
+	h.t.Errorf("unexpected %s log: %s", entry.Level, entry.Message)
+	return nil
+}
+
+// SetFailOnError marks t as failed whenever an Error or Fatal line is logged
+// until the test finishes.
+func SetFailOnError(t testing.TB) {
+	hook := &failOnErrorHook{t: t}
+	logruswrapper.AddHook(hook)
+	t.Cleanup(func() {
+		logruswrapper.RemoveHook(hook)
+	})
+}
+
+type recordingHook struct {
+	mu      sync.Mutex
+	entries []recordedEntry
+}
+
+type recordedEntry struct {
+	level logrus.Level
+	msg   string
+}
+
+func (h *recordingHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel, logrus.WarnLevel}
+}
+
+func (h *recordingHook) Fire(entry *logrus.Entry) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.entries = append(h.entries, recordedEntry{level: entry.Level, msg: entry.Message})
+	return nil
+}
+
+// ExpectNoWarnings records Warn and more severe lines and returns a function
+// that fails t if any were logged whose message is not in allow:
+//
+//	defer logtest.ExpectNoWarnings(t, "cache miss")()
+func ExpectNoWarnings(t testing.TB, allow ...string) func() {
+	allowed := make(map[string]bool, len(allow))
+	for _, msg := range allow {
+		allowed[msg] = true
+	}
+
+	hook := &recordingHook{}
+	logruswrapper.AddHook(hook)
+
+	return func() {
+		t.Helper()
+		logruswrapper.RemoveHook(hook)
+
+		hook.mu.Lock()
+		defer hook.mu.Unlock()
+		for _, e := range hook.entries {
+			if !allowed[e.msg] {
+				t.Errorf("unexpected %s log: %s", e.level, e.msg)
+			}
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package logtest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	logruswrapper "github.com/nandhasuhendra/logrus-wrapper"
+	"github.com/sirupsen/logrus"
+)
+
+func TestCollector_DrainsAsyncEntriesInOrder(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logruswrapper.SetOutput(buf)
+	defer logruswrapper.SetOutput(os.Stdout)
+
+	logruswrapper.SetAsync(16)
+	defer logruswrapper.Close()
+
+	ctx := context.Background()
+	logruswrapper.Info(ctx, "before collecting", nil)
+
+	c := Collect(t)
+	logruswrapper.Info(ctx, "first", nil)
+	logruswrapper.Warn(ctx, "second", &logruswrapper.Fields{"k": "v"})
+
+	entries := c.Drain(ctx)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 drained entries, got %d", len(entries))
+	}
+	if entries[0].Message != "first" || entries[0].Level != logrus.InfoLevel {
+		t.Errorf("expected first Info entry, got %s %q", entries[0].Level, entries[0].Message)
+	}
+	if entries[1].Message != "second" || entries[1].Data["k"] != "v" {
+		t.Errorf("expected second Warn entry with its fields, got %q %v", entries[1].Message, entries[1].Data)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("second")) {
+		t.Error("expected the async queue to be flushed to the output")
+	}
+	if again := c.Drain(ctx); len(again) != 0 {
+		t.Errorf("expected nothing left after draining, got %d entries", len(again))
+	}
+}
+
+func TestCollect_StopsWhenTestFinishes(t *testing.T) {
+	var c *Collector
+	t.Run("collecting", func(t *testing.T) {
+		c = Collect(t)
+	})
+
+	logruswrapper.SetOutput(&bytes.Buffer{})
+	defer logruswrapper.SetOutput(os.Stdout)
+	logruswrapper.Info(context.Background(), "after the test", nil)
+
+	if entries := c.Drain(context.Background()); len(entries) != 0 {
+		t.Errorf("expected no entries once the test finished, got %d", len(entries))
+	}
+}
+
+// stubTB records failures and cleanups instead of acting on them.
+type stubTB struct {
+	testing.TB
+	errors   []string
+	cleanups []func()
+}
+
+func (s *stubTB) Helper() {}
+
+func (s *stubTB) Errorf(format string, args ...interface{}) {
+	s.errors = append(s.errors, fmt.Sprintf(format, args...))
+}
+
+func (s *stubTB) Cleanup(fn func()) {
+	s.cleanups = append(s.cleanups, fn)
+}
+
+func (s *stubTB) runCleanups() {
+	for i := len(s.cleanups) - 1; i >= 0; i-- {
+		s.cleanups[i]()
+	}
+}
+
+func TestSetFailOnError_RecordsFailureOnError(t *testing.T) {
+	logruswrapper.SetOutput(&bytes.Buffer{})
+	defer logruswrapper.SetOutput(os.Stdout)
+
+	stub := &stubTB{}
+	SetFailOnError(stub)
+
+	ctx := context.Background()
+	logruswrapper.Info(ctx, "all good", nil)
+	if len(stub.errors) != 0 {
+		t.Fatalf("expected no failure for Info, got %v", stub.errors)
+	}
+
+	logruswrapper.Error(ctx, "db unavailable", errors.New("dial timeout"), nil)
+	if len(stub.errors) != 1 {
+		t.Fatalf("expected 1 recorded failure, got %d", len(stub.errors))
+	}
+	if !strings.Contains(stub.errors[0], "db unavailable") {
+		t.Errorf("expected failure to contain logged message, got %q", stub.errors[0])
+	}
+
+	stub.runCleanups()
+	logruswrapper.Error(ctx, "after cleanup", errors.New("ignored"), nil)
+	if len(stub.errors) != 1 {
+		t.Errorf("expected hook to be removed on cleanup, got %d failures", len(stub.errors))
+	}
+}
+
+func TestExpectNoWarnings_FlagsUnexpectedWarnings(t *testing.T) {
+	logruswrapper.SetOutput(&bytes.Buffer{})
+	defer logruswrapper.SetOutput(os.Stdout)
+
+	stub := &stubTB{}
+	check := ExpectNoWarnings(stub, "cache miss")
+
+	ctx := context.Background()
+	logruswrapper.Info(ctx, "not a warning", nil)
+	logruswrapper.Warn(ctx, "cache miss", nil)
+	logruswrapper.Warn(ctx, "disk nearly full", nil)
+	logruswrapper.Error(ctx, "write failed", errors.New("EIO"), nil)
+	check()
+
+	if len(stub.errors) != 2 {
+		t.Fatalf("expected 2 recorded failures, got %d: %v", len(stub.errors), stub.errors)
+	}
+	if !strings.Contains(stub.errors[0], "disk nearly full") || !strings.Contains(stub.errors[1], "write failed") {
+		t.Errorf("expected failures for the unexpected lines, got %v", stub.errors)
+	}
+
+	logruswrapper.Warn(ctx, "after check", nil)
+	if len(stub.errors) != 2 {
+		t.Error("expected recording to stop once the check has run")
+	}
+}
+
+func TestExpectNoWarnings_AllowlistedOnly(t *testing.T) {
+	logruswrapper.SetOutput(&bytes.Buffer{})
+	defer logruswrapper.SetOutput(os.Stdout)
+
+	stub := &stubTB{}
+	check := ExpectNoWarnings(stub, "cache miss", "retrying")
+
+	logruswrapper.Warn(context.Background(), "cache miss", nil)
+	logruswrapper.Warn(context.Background(), "retrying", nil)
+	check()
+
+	if len(stub.errors) != 0 {
+		t.Errorf("expected no failures for allowlisted warnings, got %v", stub.errors)
+	}
+}
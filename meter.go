@@ -0,0 +1,89 @@
+package logruswrapper
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// meterInterval is how often meters log their rate.
+var meterInterval = time.Minute
+
+// Meter counts events and logs their rate at Info every interval with the
+// "meter", "count" and "rate_per_sec" fields. Mark is safe for concurrent
+// use.
+type Meter struct {
+	name  string
+	count atomic.Int64
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewMeter starts a Meter named name. Call Close to stop it.
+func NewMeter(name string) *Meter {
+	m := &Meter{
+		name: name,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go m.run()
+
+	return m
+}
+
+// Mark records n events.
+func (m *Meter) Mark(n int) {
+	m.count.Add(int64(n))
+}
+
+// Close stops the meter, logging the events marked since the last line.
+func (m *Meter) Close() {
+	m.closeOnce.Do(func() {
+		close(m.stop)
+		<-m.done
+	})
+}
+
+func (m *Meter) run() {
+	defer close(m.done)
+
+	ticker := time.NewTicker(meterInterval)
+	defer ticker.Stop()
+
+	last := time.Now()
+	for {
+		select {
+		case <-m.stop:
+			if m.count.Load() > 0 {
+				m.log(time.Since(last))
+			}
+			return
+		case t := <-ticker.C:
+			m.log(t.Sub(last))
+			last = t
+		}
+	}
+}
+
+func (m *Meter) log(elapsed time.Duration) {
+	count := m.count.Swap(0)
+	if !enabled(context.Background(), logrus.InfoLevel, "meter") {
+		return
+	}
+	rate := 0.0
+	if elapsed > 0 {
+		rate = float64(count) / elapsed.Seconds()
+	}
+
+	fields := Fields{
+		"meter":        m.name,
+		"count":        count,
+		"rate_per_sec": rate,
+	}
+	generateLogger(context.Background(), &fields).Info("meter")
+}
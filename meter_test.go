@@ -0,0 +1,92 @@
+package logruswrapper
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestMeter_LogsRate(t *testing.T) {
+	captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	buf := &lockedBuffer{}
+	log.SetOutput(buf)
+
+	meterInterval = 50 * time.Millisecond
+	defer func() { meterInterval = time.Minute }()
+
+	m := NewMeter("jobs")
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.Mark(5)
+		}()
+	}
+	wg.Wait()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(buf.String(), `"meter":"jobs"`) && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	m.Close()
+
+	line := strings.SplitN(buf.String(), "\n", 2)[0]
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		t.Fatalf("expected a JSON meter line, got %q: %v", line, err)
+	}
+
+	if entry["meter"] != "jobs" || entry["count"] != float64(50) {
+		t.Errorf("expected 50 events for meter 'jobs', got %v", entry)
+	}
+	if rate, ok := entry["rate_per_sec"].(float64); !ok || rate <= 0 {
+		t.Errorf("expected a positive rate_per_sec, got %v", entry["rate_per_sec"])
+	}
+}
+
+func TestMeter_CloseFlushesRemainder(t *testing.T) {
+	captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	buf := &lockedBuffer{}
+	log.SetOutput(buf)
+
+	m := NewMeter("flushes")
+	m.Mark(3)
+	m.Close()
+	m.Close()
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(buf.String()), &entry); err != nil {
+		t.Fatalf("expected a single JSON meter line, got %q: %v", buf.String(), err)
+	}
+	if entry["count"] != float64(3) {
+		t.Errorf("expected the remaining 3 events on Close, got %v", entry["count"])
+	}
+}
+
+func TestMeter_SuppressedBelowInfo(t *testing.T) {
+	captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.ErrorLevel)
+
+	buf := &lockedBuffer{}
+	log.SetOutput(buf)
+
+	m := NewMeter("quiet")
+	m.Mark(3)
+	m.Close()
+
+	if buf.String() != "" {
+		t.Errorf("expected no meter line at Error level, got %q", buf.String())
+	}
+}
@@ -0,0 +1,72 @@
+package logruswrapper
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// levelCounts holds how many lines were written at each level, indexed by
+// logrus.Level.
+var levelCounts [logrus.TraceLevel + 1]atomic.Uint64
+
+// Counter is the subset of a metrics counter the wrapper needs. A
+// prometheus.Counter satisfies it without this package depending on
+// Prometheus.
+type Counter interface {
+	Inc()
+}
+
+// SetErrorCounter increments c for every Error and Fatal line written.
+// Passing nil removes the counter.
+func SetErrorCounter(c Counter) {
+	updateOptions(func(o *options) { o.errorCounter = c })
+}
+
+// errorLogged runs the bookkeeping for an Error or Fatal line that has just
+// been logged at level.
+func errorLogged(level logrus.Level) {
+	if currentLevel() < level {
+		return
+	}
+
+	if c := loadOptions().errorCounter; c != nil {
+		c.Inc()
+	}
+	recordError()
+}
+
+// levelCountHook feeds levelCounts from every written entry.
+type levelCountHook struct{}
+
+func (levelCountHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (levelCountHook) Fire(entry *logrus.Entry) error {
+	if int(entry.Level) < len(levelCounts) {
+		levelCounts[entry.Level].Add(1)
+	}
+
+	return nil
+}
+
+// LogSummary writes one Info line with the number of lines logged so far at
+// each level, e.g. as the last line before shutdown.
+func LogSummary(ctx context.Context) {
+	if !enabled(ctx, logrus.InfoLevel, "log summary") {
+		return
+	}
+
+	fields := Fields{
+		"trace_count": levelCounts[logrus.TraceLevel].Load(),
+		"debug_count": levelCounts[logrus.DebugLevel].Load(),
+		"info_count":  levelCounts[logrus.InfoLevel].Load(),
+		"warn_count":  levelCounts[logrus.WarnLevel].Load(),
+		"error_count": levelCounts[logrus.ErrorLevel].Load(),
+	}
+
+	callerFields := getCaller(logrus.InfoLevel)
+	generateLogger(ctx, &fields).WithFields(*callerFields).Info("log summary")
+}
@@ -0,0 +1,93 @@
+package logruswrapper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+type fakeCounter struct {
+	n atomic.Int64
+}
+
+func (c *fakeCounter) Inc() {
+	c.n.Add(1)
+}
+
+func TestSetErrorCounter_IncrementsPerError(t *testing.T) {
+	captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	counter := &fakeCounter{}
+	SetErrorCounter(counter)
+	defer SetErrorCounter(nil)
+
+	ctx := context.Background()
+	Info(ctx, "not counted", nil)
+	Warn(ctx, "not counted", nil)
+	Error(ctx, "first", errors.New("boom"), nil)
+	Error(ctx, "second", errors.New("boom"), nil)
+	Log(ctx, logrus.ErrorLevel, "third", nil)
+
+	if got := counter.n.Load(); got != 3 {
+		t.Errorf("expected counter to be incremented 3 times, got %d", got)
+	}
+}
+
+func TestSetErrorCounter_IncrementsOnFatal(t *testing.T) {
+	captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	counter := &fakeCounter{}
+	SetErrorCounter(counter)
+	defer SetErrorCounter(nil)
+
+	exitCode := -1
+	log.ExitFunc = func(code int) { exitCode = code }
+	defer func() { log.ExitFunc = nil }()
+
+	Fatal(context.Background(), "fatal", nil)
+
+	if got := counter.n.Load(); got != 1 {
+		t.Errorf("expected counter to be incremented once, got %d", got)
+	}
+	if exitCode != 1 {
+		t.Errorf("expected exit code 1, got %d", exitCode)
+	}
+}
+
+func TestLogSummary_ReportsLevelCounts(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+	for i := range levelCounts {
+		levelCounts[i].Store(0)
+	}
+
+	ctx := context.Background()
+	Info(ctx, "one", nil)
+	Info(ctx, "two", nil)
+	Warn(ctx, "three", nil)
+	Error(ctx, "four", errors.New("boom"), nil)
+	Debug(ctx, "suppressed", nil)
+
+	buf.Reset()
+	LogSummary(ctx)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+	want := map[string]float64{"info_count": 2, "warn_count": 1, "error_count": 1, "debug_count": 0}
+	for k, v := range want {
+		if entry[k] != v {
+			t.Errorf("expected %s %v, got %v", k, v, entry[k])
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package logruswrapper
+
+import (
+	"bytes"
+	"reflect"
+	"runtime"
+	"strconv"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	optsMu sync.RWMutex
+	opts   = options{callerFile: true, callerFunc: true}
+)
+
+type options struct {
+	utc      bool
+	severity bool
+	goid     bool
+	nilError NilErrorPolicy
+
+	queuePolicy QueueFullPolicy
+
+	callerFile bool
+	callerFunc bool
+
+	errorCallerFrames int
+
+	// callerLevels, when non-nil, restricts caller fields to its levels.
+	callerLevels map[logrus.Level]bool
+
+	customCaller func(skip int) Fields
+
+	fatalPanics bool
+
+	keyCase KeyCase
+
+	byteSliceEncoding ByteSliceEncoding
+
+	typeFormatters map[reflect.Type]func(interface{}) interface{}
+
+	nestFieldsKey string
+
+	maxFieldValueLength int
+
+	valueRedactions []valueRedaction
+
+	observer func(*logrus.Entry)
+
+	errorCounter Counter
+
+	version string
+	commit  string
+
+	extractors []IDExtractor
+
+	middlewares []func(*logrus.Entry) *logrus.Entry
+
+	packageLevels []packageLevelOverride
+
+	logErrorType bool
+
+	timestampFormat string
+}
+
+// NilErrorPolicy controls how Error behaves when called with a nil error.
+type NilErrorPolicy int
+
+const (
+	// NilErrorOmitField logs at Error level without an "error" field but
+	// with "missing_error": true, so call sites that forgot the error can
+	// be found.
+	NilErrorOmitField NilErrorPolicy = iota
+	// NilErrorDowngradeToWarn logs the line at Warn level instead.
+	NilErrorDowngradeToWarn
+)
+
+var gcpSeverities = map[logrus.Level]string{
+	logrus.PanicLevel: "ALERT",
+	logrus.FatalLevel: "CRITICAL",
+	logrus.ErrorLevel: "ERROR",
+	logrus.WarnLevel:  "WARNING",
+	logrus.InfoLevel:  "INFO",
+	logrus.DebugLevel: "DEBUG",
+	logrus.TraceLevel: "DEBUG",
+}
+
+func loadOptions() options {
+	optsMu.RLock()
+	defer optsMu.RUnlock()
+
+	return opts
+}
+
+func updateOptions(fn func(*options)) {
+	optsMu.Lock()
+	defer optsMu.Unlock()
+
+	fn(&opts)
+}
+
+func SetUTC(enabled bool) {
+	updateOptions(func(o *options) { o.utc = enabled })
+}
+
+// SetSeverityField adds a GCP Cloud Logging style "severity" field alongside
+// the standard level.
+func SetSeverityField(enabled bool) {
+	updateOptions(func(o *options) { o.severity = enabled })
+}
+
+// SetIncludeGoroutineID adds a "goid" field to Debug and Trace lines. It is
+// meant for diagnosing concurrency issues and costs a runtime.Stack call per line.
+func SetIncludeGoroutineID(enabled bool) {
+	updateOptions(func(o *options) { o.goid = enabled })
+}
+
+func SetNilErrorPolicy(policy NilErrorPolicy) {
+	updateOptions(func(o *options) { o.nilError = policy })
+}
+
+// SetCallerFields selects which caller fields are attached. Disabling both
+// skips the caller lookup entirely.
+func SetCallerFields(file bool, fn bool) {
+	updateOptions(func(o *options) {
+		o.callerFile = file
+		o.callerFunc = fn
+	})
+}
+
+// SetCallerFunc replaces the built-in file/func caller fields with whatever
+// fn returns. fn receives the skip to pass to runtime.Caller to reach the
+// log call site. Passing nil restores the default, including the
+// SetCallerFields settings.
+func SetCallerFunc(fn func(skip int) Fields) {
+	updateOptions(func(o *options) { o.customCaller = fn })
+}
+
+// SetCallerLevels attaches caller fields only to lines at the given levels,
+// e.g. Error and Warn; other levels skip the caller lookup altogether.
+// Calling it with no levels attaches them at every level again.
+func SetCallerLevels(levels ...logrus.Level) {
+	var set map[logrus.Level]bool
+	if len(levels) > 0 {
+		set = make(map[logrus.Level]bool, len(levels))
+		for _, level := range levels {
+			set[level] = true
+		}
+	}
+
+	updateOptions(func(o *options) { o.callerLevels = set })
+}
+
+// SetErrorCallerFrames adds a "callers" array with the top n stack frames
+// ("file:line:func") to Error and Fatal lines. n <= 0 disables it.
+func SetErrorCallerFrames(n int) {
+	updateOptions(func(o *options) { o.errorCallerFrames = n })
+}
+
+// SetFatalPanics makes Fatal panic with the message after logging instead of
+// exiting, so deferred cleanup runs and a top-level recover can coordinate
+// shutdown.
+func SetFatalPanics(enabled bool) {
+	updateOptions(func(o *options) { o.fatalPanics = enabled })
+}
+
+// SetLogErrorType adds an "error_type" field with the Go type of the error
+// passed to Error, plus "error_root_type" for the innermost wrapped error.
+func SetLogErrorType(enabled bool) {
+	updateOptions(func(o *options) { o.logErrorType = enabled })
+}
+
+// resolveValues evaluates lazy field values and drops level-scoped ones that
+// do not apply at level, then redacts and truncates the string and error
+// values left, so values only known at write time are covered too.
+func resolveValues(level logrus.Level, data Fields, o *options) {
+	var truncated []string
+	for k, v := range data {
+		if scoped, ok := v.(levelScopedValue); ok {
+			if level < scoped.level {
+				delete(data, k)
+				continue
+			}
+			v = scoped.value
+			data[k] = v
+		}
+		if lazy, ok := v.(lazyValue); ok {
+			v = lazy.fn()
+			data[k] = v
+		}
+
+		var str string
+		switch v := v.(type) {
+		case string:
+			str = v
+		case error:
+			if len(o.valueRedactions) == 0 && o.maxFieldValueLength <= 0 {
+				continue
+			}
+			str = v.Error()
+		default:
+			continue
+		}
+		out := redactValue(str, o.valueRedactions)
+		if o.maxFieldValueLength > 0 && len(out) > o.maxFieldValueLength {
+			out = truncateString(out, o.maxFieldValueLength)
+			truncated = append(truncated, k)
+		}
+		if out != str {
+			data[k] = out
+		}
+	}
+	for _, k := range truncated {
+		data[k+"_truncated"] = true
+	}
+}
+
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+
+	return id
+}
+
+// optionsHook applies the package options to every entry before it is
+// formatted.
+type optionsHook struct{}
+
+func (optionsHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (optionsHook) Fire(entry *logrus.Entry) error {
+	o := loadOptions()
+	resolveValues(entry.Level, entry.Data, &o)
+	if o.nestFieldsKey != "" {
+		if nested, ok := entry.Data[o.nestFieldsKey].(Fields); ok {
+			resolveValues(entry.Level, nested, &o)
+		}
+	}
+	entry.Message = redactValue(entry.Message, o.valueRedactions)
+	if o.utc {
+		entry.Time = entry.Time.UTC()
+	}
+	if o.severity {
+		entry.Data["severity"] = gcpSeverities[entry.Level]
+	}
+	if o.version != "" {
+		entry.Data["version"] = o.version
+	}
+	if o.commit != "" {
+		entry.Data["commit"] = o.commit
+	}
+	if o.goid && entry.Level >= logrus.DebugLevel {
+		entry.Data["goid"] = goroutineID()
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package logruswrapper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestSetUTC_RendersUTCTimestamps(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	local := time.Local
+	time.Local = time.FixedZone("UTC+7", 7*60*60)
+	defer func() { time.Local = local }()
+
+	SetUTC(true)
+	defer SetUTC(false)
+
+	Info(context.Background(), "utc message", nil)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	ts, _ := entry["time"].(string)
+	if !strings.HasSuffix(ts, "Z") {
+		t.Errorf("expected UTC timestamp ending in Z, got %q", ts)
+	}
+}
+
+func TestSetUTC_DisabledKeepsLocalTime(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	local := time.Local
+	time.Local = time.FixedZone("UTC+7", 7*60*60)
+	defer func() { time.Local = local }()
+
+	Info(context.Background(), "local message", nil)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	ts, _ := entry["time"].(string)
+	if !strings.HasSuffix(ts, "+07:00") {
+		t.Errorf("expected local +07:00 timestamp, got %q", ts)
+	}
+}
+
+func TestSetSeverityField_AddsUppercaseSeverity(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	SetSeverityField(true)
+	defer SetSeverityField(false)
+
+	ctx := context.Background()
+	Error(ctx, "boom", errors.New("kaput"), nil)
+	Warn(ctx, "careful", nil)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %s", len(lines), buf.String())
+	}
+
+	for i, want := range []string{"ERROR", "WARNING"} {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(lines[i]), &entry); err != nil {
+			t.Fatalf("expected valid JSON output: %v", err)
+		}
+		if entry["severity"] != want {
+			t.Errorf("expected severity %q, got %v", want, entry["severity"])
+		}
+		if _, ok := entry["level"]; !ok {
+			t.Error("expected standard level field to be kept")
+		}
+	}
+}
+
+func TestSetIncludeGoroutineID_DistinctPerGoroutine(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.DebugLevel)
+	defer setLevel(logrus.InfoLevel)
+
+	SetIncludeGoroutineID(true)
+	defer SetIncludeGoroutineID(false)
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Debug(ctx, "from goroutine", nil)
+		}()
+	}
+	wg.Wait()
+	Info(ctx, "info line", nil)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d: %s", len(lines), buf.String())
+	}
+
+	ids := map[float64]bool{}
+	for _, line := range lines {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("expected valid JSON output: %v", err)
+		}
+		goid, ok := entry["goid"].(float64)
+		if entry["level"] == "info" {
+			if ok {
+				t.Error("expected no goid on Info lines")
+			}
+			continue
+		}
+		if !ok || goid == 0 {
+			t.Fatalf("expected non-zero goid on debug line, got %v", entry["goid"])
+		}
+		ids[goid] = true
+	}
+
+	if len(ids) != 2 {
+		t.Errorf("expected 2 distinct goid values, got %v", ids)
+	}
+}
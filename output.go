@@ -0,0 +1,258 @@
+package logruswrapper
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// output is the destination chosen through SetOutput. The writer
+	// installed on the logger wraps it according to the output options.
+	output io.Writer = os.Stdout
+
+	lineTerminator = "\n"
+
+	slowLogThreshold time.Duration
+	// metaOutput receives warnings about the logging pipeline itself. It
+	// must not go through the (possibly slow or failing) main output.
+	metaOutput io.Writer = os.Stderr
+
+	// fallbackOutput receives lines the primary output failed to write.
+	fallbackOutput io.Writer = os.Stderr
+)
+
+func SetOutput(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	output = w
+	applyOutput()
+}
+
+// SetOutputFD writes to an already open file descriptor, e.g. fd 3 handed
+// over by a supervisor. The wrapper takes ownership of fd; if it is not
+// open for writing, fd is closed and an error returned.
+func SetOutputFD(fd uintptr) error {
+	f := os.NewFile(fd, fmt.Sprintf("fd%d", fd))
+	if f == nil {
+		return fmt.Errorf("invalid file descriptor %d", fd)
+	}
+	if _, err := f.Write(nil); err != nil {
+		f.Close()
+		return fmt.Errorf("file descriptor %d is not writable, %w", fd, err)
+	}
+
+	SetOutput(f)
+
+	return nil
+}
+
+// applyOutput rebuilds the writer chain around output and installs it on
+// the logger. mu must be held.
+func applyOutput() {
+	w := output
+	if fallbackOutput != nil {
+		w = &fallbackWriter{primary: w, fallback: fallbackOutput}
+	}
+	if slowLogThreshold > 0 {
+		w = &slowWriter{out: w, threshold: slowLogThreshold, formatter: log.Formatter}
+	}
+	if lineTerminator != "\n" {
+		w = &terminatorWriter{out: w, terminator: []byte(lineTerminator)}
+	}
+	if ring != nil {
+		w = &ringWriter{out: w, ring: ring}
+	}
+
+	if async != nil {
+		async.setOut(w)
+		w = async
+	}
+	log.SetOutput(w)
+}
+
+// SetOutputs writes every line to all of writers. A failing writer does not
+// prevent the remaining ones from receiving the line, and the line only goes
+// to the fallback output when every writer failed.
+func SetOutputs(writers ...io.Writer) {
+	if len(writers) == 1 {
+		SetOutput(writers[0])
+		return
+	}
+
+	SetOutput(multiWriter(append([]io.Writer(nil), writers...)))
+}
+
+type multiWriter []io.Writer
+
+// Write reports an error only when no writer accepted p, so a single bad
+// sink does not make fallbackWriter duplicate the line.
+func (mw multiWriter) Write(p []byte) (int, error) {
+	var firstErr error
+	delivered := false
+	for _, w := range mw {
+		n, err := w.Write(p)
+		if err == nil && n != len(p) {
+			err = io.ErrShortWrite
+		}
+		if err == nil {
+			delivered = true
+		} else if firstErr == nil {
+			firstErr = err
+		}
+	}
+	if delivered {
+		return len(p), nil
+	}
+
+	return 0, firstErr
+}
+
+// SetLineTerminator sets what ends each written line, e.g. "\r\n" for
+// Windows consumers or "" for none. The default is "\n".
+func SetLineTerminator(s string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	lineTerminator = s
+	applyOutput()
+}
+
+// terminatorWriter replaces the trailing newline of each formatted line
+// with terminator.
+type terminatorWriter struct {
+	out        io.Writer
+	terminator []byte
+}
+
+func (w *terminatorWriter) Write(p []byte) (int, error) {
+	line := bytes.TrimSuffix(p, []byte("\n"))
+	buf := make([]byte, 0, len(line)+len(w.terminator))
+	buf = append(append(buf, line...), w.terminator...)
+
+	if _, err := w.out.Write(buf); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// SetSlowLogThreshold reports, once, on stderr when writing a single line
+// to the output takes longer than d. A d <= 0 disables the check.
+func SetSlowLogThreshold(d time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	slowLogThreshold = d
+	applyOutput()
+}
+
+// slowWriter times writes to out and emits a single meta-warning to
+// metaOutput the first time one exceeds threshold. The warning is formatted
+// with formatter, captured under mu when the writer chain is built, because
+// warn may run on the async writer's goroutine where log.Formatter cannot be
+// read safely.
+type slowWriter struct {
+	out       io.Writer
+	threshold time.Duration
+	formatter logrus.Formatter
+	warned    atomic.Bool
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	start := time.Now()
+	n, err := w.out.Write(p)
+	elapsed := time.Since(start)
+
+	if elapsed > w.threshold && w.warned.CompareAndSwap(false, true) {
+		w.warn(elapsed)
+	}
+
+	return n, err
+}
+
+func (w *slowWriter) warn(elapsed time.Duration) {
+	entry := logrus.NewEntry(log).WithFields(Fields{
+		"duration_ms":  elapsed.Milliseconds(),
+		"threshold_ms": w.threshold.Milliseconds(),
+	})
+	entry.Time = time.Now()
+	entry.Level = logrus.WarnLevel
+	entry.Message = "slow log write detected"
+
+	b, err := w.formatter.Format(entry)
+	if err != nil {
+		b = []byte(fmt.Sprintf("level=warning msg=%q duration=%s\n", entry.Message, elapsed))
+	}
+	_, _ = metaOutput.Write(b)
+}
+
+// SetFallbackOutput sets where a line is written when writing it to the
+// primary output fails. The default is os.Stderr; nil disables the retry.
+func SetFallbackOutput(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	fallbackOutput = w
+	applyOutput()
+}
+
+// fallbackWriter retries writes that fail on primary against fallback.
+type fallbackWriter struct {
+	primary  io.Writer
+	fallback io.Writer
+}
+
+func (w *fallbackWriter) Write(p []byte) (int, error) {
+	n, err := w.primary.Write(p)
+	if err == nil && n != len(p) {
+		err = io.ErrShortWrite
+	}
+	if err == nil {
+		return n, nil
+	}
+
+	if _, fallbackErr := w.fallback.Write(p); fallbackErr != nil {
+		return n, err
+	}
+
+	return len(p), nil
+}
+
+// AddTee also writes every line to w, formatted with formatter independently
+// of the primary formatter, e.g. text on the console next to JSON in a
+// file.
+func AddTee(formatter logrus.Formatter, w io.Writer) {
+	addHook(&teeHook{formatter: formatter, out: w})
+}
+
+type teeHook struct {
+	formatter logrus.Formatter
+
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func (h *teeHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *teeHook) Fire(entry *logrus.Entry) error {
+	b, err := h.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err = h.out.Write(b)
+
+	return err
+}
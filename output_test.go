@@ -0,0 +1,318 @@
+package logruswrapper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type failingWriter struct {
+	err error
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	return 0, w.err
+}
+
+func TestSetOutputs_FansOutToAllWriters(t *testing.T) {
+	captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	first, second := &bytes.Buffer{}, &bytes.Buffer{}
+	SetOutputs(first, second)
+
+	ctx := context.Background()
+	Info(ctx, "line one", nil)
+	Info(ctx, "line two", nil)
+
+	for name, buf := range map[string]*bytes.Buffer{"first": first, "second": second} {
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("expected 2 lines in %s writer, got %d: %s", name, len(lines), buf.String())
+		}
+		if !strings.Contains(lines[0], "line one") || !strings.Contains(lines[1], "line two") {
+			t.Errorf("unexpected content in %s writer: %s", name, buf.String())
+		}
+	}
+}
+
+func TestSetOutputs_FailingWriterDoesNotBlockOthers(t *testing.T) {
+	captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	healthy := &bytes.Buffer{}
+	SetOutputs(&failingWriter{err: errors.New("disk full")}, healthy)
+
+	Info(context.Background(), "still delivered", nil)
+
+	if !strings.Contains(healthy.String(), "still delivered") {
+		t.Errorf("expected healthy writer to receive the line, got: %s", healthy.String())
+	}
+}
+
+func TestSetOutputs_FallbackOnlyWhenAllFail(t *testing.T) {
+	captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	fallback := &bytes.Buffer{}
+	SetFallbackOutput(fallback)
+	defer SetFallbackOutput(os.Stderr)
+
+	SetOutputs(&failingWriter{err: errors.New("disk full")}, &bytes.Buffer{})
+	Info(context.Background(), "delivered once", nil)
+	if fallback.Len() != 0 {
+		t.Errorf("expected no fallback write while another output succeeded, got %q", fallback.String())
+	}
+
+	SetOutputs(&failingWriter{err: errors.New("disk full")}, &failingWriter{err: errors.New("broken pipe")})
+	Info(context.Background(), "nowhere else", nil)
+	if !strings.Contains(fallback.String(), "nowhere else") {
+		t.Errorf("expected the fallback to receive a line no output accepted, got %q", fallback.String())
+	}
+}
+
+func TestSetLineTerminator(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	SetLineTerminator("\r\n")
+	defer SetLineTerminator("\n")
+
+	ctx := context.Background()
+	Info(ctx, "first", nil)
+	Info(ctx, "second", nil)
+
+	lines := strings.Split(buf.String(), "\r\n")
+	if len(lines) != 3 || lines[2] != "" {
+		t.Fatalf("expected two CRLF-terminated lines, got %q", buf.String())
+	}
+	for _, line := range lines[:2] {
+		if strings.Contains(line, "\n") {
+			t.Errorf("expected no bare newline within a line, got %q", line)
+		}
+	}
+}
+
+func TestSetLineTerminator_Empty(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	SetLineTerminator("")
+	defer SetLineTerminator("\n")
+
+	Info(context.Background(), "no newline", nil)
+
+	if strings.HasSuffix(buf.String(), "\n") {
+		t.Errorf("expected no trailing newline, got %q", buf.String())
+	}
+}
+
+type slowTestWriter struct {
+	delay time.Duration
+	buf   bytes.Buffer
+}
+
+func (w *slowTestWriter) Write(p []byte) (int, error) {
+	time.Sleep(w.delay)
+	return w.buf.Write(p)
+}
+
+func TestSetSlowLogThreshold_WarnsOnceOnMetaOutput(t *testing.T) {
+	captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	meta := &bytes.Buffer{}
+	metaOutput = meta
+	defer func() { metaOutput = os.Stderr }()
+
+	slow := &slowTestWriter{delay: 20 * time.Millisecond}
+	SetOutput(slow)
+	SetSlowLogThreshold(5 * time.Millisecond)
+	defer SetSlowLogThreshold(0)
+
+	ctx := context.Background()
+	Info(ctx, "first", nil)
+	Info(ctx, "second", nil)
+
+	if strings.Count(slow.buf.String(), "\n") != 2 {
+		t.Errorf("expected both lines on the slow writer, got %q", slow.buf.String())
+	}
+	if strings.Contains(slow.buf.String(), "slow log write") {
+		t.Error("expected the meta-warning not to go through the slow writer")
+	}
+
+	if got := strings.Count(meta.String(), "slow log write detected"); got != 1 {
+		t.Fatalf("expected exactly one meta-warning, got %d: %s", got, meta.String())
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal(meta.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON meta-warning: %v", err)
+	}
+	if d, _ := entry["duration_ms"].(float64); d < 5 {
+		t.Errorf("expected duration_ms above the threshold, got %v", entry["duration_ms"])
+	}
+}
+
+func TestSetSlowLogThreshold_AsyncWithFormatterChanges(t *testing.T) {
+	captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	meta := &lockedBuffer{}
+	metaOutput = meta
+	defer func() { metaOutput = os.Stderr }()
+
+	SetOutput(&slowTestWriter{delay: 2 * time.Millisecond})
+	SetSlowLogThreshold(time.Millisecond)
+	defer SetSlowLogThreshold(0)
+	SetAsync(16)
+	defer SetAsync(0)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			SetCompactFormat(i%2 == 0)
+		}
+		SetCompactFormat(false)
+	}()
+	for i := 0; i < 5; i++ {
+		Info(context.Background(), "line", nil)
+	}
+	<-done
+	Flush()
+
+	if !strings.Contains(meta.String(), "slow log write detected") {
+		t.Errorf("expected the meta-warning, got %q", meta.String())
+	}
+}
+
+func TestSetFallbackOutput_ReceivesFailedLines(t *testing.T) {
+	captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	fallback := &bytes.Buffer{}
+	SetFallbackOutput(fallback)
+	defer SetFallbackOutput(os.Stderr)
+	SetOutput(&failingWriter{err: errors.New("broken pipe")})
+
+	Info(context.Background(), "rescued", nil)
+
+	if !strings.Contains(fallback.String(), "rescued") {
+		t.Errorf("expected fallback to receive the line, got: %q", fallback.String())
+	}
+}
+
+func TestSetFallbackOutput_UnusedWhenPrimarySucceeds(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	fallback := &bytes.Buffer{}
+	SetFallbackOutput(fallback)
+	defer SetFallbackOutput(os.Stderr)
+
+	Info(context.Background(), "primary", nil)
+
+	if !strings.Contains(buf.String(), "primary") || fallback.Len() != 0 {
+		t.Errorf("expected line only on primary, got primary=%q fallback=%q", buf.String(), fallback.String())
+	}
+}
+
+func TestSetOutputFD_WritesToDescriptor(t *testing.T) {
+	captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	// SetOutputFD takes ownership of the descriptor, so hand it a duplicate.
+	fd, err := syscall.Dup(int(w.Fd()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SetOutputFD(uintptr(fd)); err != nil {
+		t.Fatalf("expected pipe write end to be accepted, got %v", err)
+	}
+	Info(context.Background(), "via fd", nil)
+
+	line := make([]byte, 4096)
+	n, err := r.Read(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal(line[:n], &entry); err != nil {
+		t.Fatalf("expected a JSON line on the pipe: %v", err)
+	}
+	if entry["msg"] != "via fd" {
+		t.Errorf("expected msg 'via fd', got %v", entry["msg"])
+	}
+}
+
+func TestSetOutputFD_RejectsReadOnlyDescriptor(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	fd, err := syscall.Dup(int(r.Fd()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SetOutputFD(uintptr(fd)); err == nil {
+		t.Error("expected an error for the pipe's read end")
+	}
+}
+
+func TestAddTee_WritesSecondFormat(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+	log.SetFormatter(&logrus.JSONFormatter{})
+
+	hooks := make(logrus.LevelHooks)
+	for level, levelHooks := range log.Hooks {
+		hooks[level] = append([]logrus.Hook(nil), levelHooks...)
+	}
+	defer log.ReplaceHooks(hooks)
+	tee := &bytes.Buffer{}
+	AddTee(&logrus.TextFormatter{DisableColors: true}, tee)
+
+	Info(context.Background(), "teed", &Fields{"k": "v"})
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected JSON on the primary output: %v", err)
+	}
+	if entry["msg"] != "teed" {
+		t.Errorf("expected msg 'teed', got %v", entry["msg"])
+	}
+
+	line := tee.String()
+	if !strings.Contains(line, `msg=teed`) || !strings.Contains(line, "k=v") || strings.HasPrefix(line, "{") {
+		t.Errorf("expected a text line on the tee, got %q", line)
+	}
+}
@@ -0,0 +1,129 @@
+package logruswrapper
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// JSONLine is a log line produced by the JSON formatter, parsed back into
+// its parts.
+type JSONLine struct {
+	Level  logrus.Level
+	Msg    string
+	Time   time.Time
+	Fields Fields
+}
+
+// JSONLineReader is an io.Writer that parses every JSON log line written to
+// it, passes it through Transform and re-emits it through this package's
+// logger. Lines that are not valid log JSON are copied to the writer given
+// to NewJSONLineReader.
+type JSONLineReader struct {
+	// Transform, when set, may modify each line before it is re-emitted.
+	// Returning false drops the line.
+	Transform func(line *JSONLine) bool
+
+	mu      sync.Mutex
+	pending []byte
+	invalid io.Writer
+}
+
+func NewJSONLineReader(w io.Writer) *JSONLineReader {
+	return &JSONLineReader{invalid: w}
+}
+
+func (r *JSONLineReader) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pending = append(r.pending, p...)
+	for {
+		i := bytes.IndexByte(r.pending, '\n')
+		if i < 0 {
+			break
+		}
+		line := r.pending[:i]
+		r.pending = r.pending[i+1:]
+		if err := r.handle(line); err != nil {
+			return len(p), err
+		}
+	}
+
+	return len(p), nil
+}
+
+// Close processes a trailing line that was not terminated by a newline.
+func (r *JSONLineReader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	line := r.pending
+	r.pending = nil
+
+	return r.handle(line)
+}
+
+func (r *JSONLineReader) handle(raw []byte) error {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil
+	}
+
+	line, ok := ParseJSONLine(raw)
+	if !ok {
+		if r.invalid == nil {
+			return nil
+		}
+		_, err := r.invalid.Write(append(raw, '\n'))
+		return err
+	}
+
+	if r.Transform != nil && !r.Transform(line) {
+		return nil
+	}
+	if currentLevel() < line.Level {
+		return nil
+	}
+
+	entry := log.WithFields(line.Fields)
+	entry.Time = line.Time
+	entry.Log(line.Level, line.Msg)
+
+	return nil
+}
+
+// ParseJSONLine parses a single line written by the JSON formatter. It
+// reports false when raw is not a JSON object with a valid level.
+func ParseJSONLine(raw []byte) (*JSONLine, bool) {
+	var data map[string]interface{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, false
+	}
+
+	levelStr, _ := data[logrus.FieldKeyLevel].(string)
+	level, err := logrus.ParseLevel(levelStr)
+	if err != nil {
+		return nil, false
+	}
+
+	line := &JSONLine{Level: level, Fields: Fields{}}
+	line.Msg, _ = data[logrus.FieldKeyMsg].(string)
+	if ts, ok := data[logrus.FieldKeyTime].(string); ok {
+		line.Time, _ = time.Parse(time.RFC3339Nano, ts)
+	}
+
+	for k, v := range data {
+		switch k {
+		case logrus.FieldKeyLevel, logrus.FieldKeyMsg, logrus.FieldKeyTime:
+			continue
+		}
+		line.Fields[k] = v
+	}
+
+	return line, true
+}
@@ -0,0 +1,76 @@
+package logruswrapper
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestJSONLineReader_ParsesLines(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	var parsed []JSONLine
+	invalid := &bytes.Buffer{}
+	r := NewJSONLineReader(invalid)
+	r.Transform = func(line *JSONLine) bool {
+		parsed = append(parsed, *line)
+		line.Fields["relayed"] = true
+		return true
+	}
+
+	input := `{"level":"warning","msg":"disk almost full","time":"2026-03-01T10:00:00Z","disk":"/dev/sda1"}` + "\n" +
+		`{"level":"error","msg":"disk full","time":"2026-03-01T10:05:00Z","error":"ENOSPC"}` + "\n" +
+		"not json\n"
+	// Split the write mid-line to exercise buffering of partial lines.
+	if _, err := r.Write([]byte(input[:40])); err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+	if _, err := r.Write([]byte(input[40:])); err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+
+	if len(parsed) != 2 {
+		t.Fatalf("expected 2 parsed lines, got %d", len(parsed))
+	}
+
+	first := parsed[0]
+	if first.Level != logrus.WarnLevel || first.Msg != "disk almost full" {
+		t.Errorf("unexpected first line: %+v", first)
+	}
+	if !first.Time.Equal(time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected parsed time, got %v", first.Time)
+	}
+	if first.Fields["disk"] != "/dev/sda1" {
+		t.Errorf("expected disk field, got %v", first.Fields)
+	}
+	if _, ok := first.Fields["msg"]; ok {
+		t.Error("expected standard keys not to be duplicated in Fields")
+	}
+
+	second := parsed[1]
+	if second.Level != logrus.ErrorLevel || second.Msg != "disk full" || second.Fields["error"] != "ENOSPC" {
+		t.Errorf("unexpected second line: %+v", second)
+	}
+
+	if invalid.String() != "not json\n" {
+		t.Errorf("expected invalid line to be passed through, got %q", invalid.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 re-emitted lines, got %d: %s", len(lines), buf.String())
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+	if entry["relayed"] != true || entry["msg"] != "disk almost full" {
+		t.Errorf("expected transformed line to be re-emitted, got %v", entry)
+	}
+}
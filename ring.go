@@ -0,0 +1,99 @@
+package logruswrapper
+
+import (
+	"io"
+	"sync"
+)
+
+// ring holds the most recent formatted lines while EnableRingBuffer is on.
+// The pointer is guarded by mu.
+var ring *ringBuffer
+
+// EnableRingBuffer keeps the last size formatted lines in memory so they can
+// be written out with DumpRingBuffer after the fact. They are dumped to
+// stderr automatically when a Fatal or Panic line is logged. A size <= 0
+// disables the buffer.
+func EnableRingBuffer(size int) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	ring = nil
+	if size > 0 {
+		ring = &ringBuffer{lines: make([][]byte, size)}
+	}
+	applyOutput()
+}
+
+// DumpRingBuffer writes the retained lines to w, oldest first.
+func DumpRingBuffer(w io.Writer) error {
+	mu.Lock()
+	r := ring
+	mu.Unlock()
+	if r == nil {
+		return nil
+	}
+
+	for _, line := range r.snapshot() {
+		if _, err := w.Write(line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// crashDump writes the ring buffer to metaOutput before the process dies.
+// It drains the async queue first, so the final line and everything queued
+// before it are written and retained in the ring.
+func crashDump() {
+	Flush()
+	_ = DumpRingBuffer(metaOutput)
+}
+
+type ringBuffer struct {
+	mu    sync.Mutex
+	lines [][]byte
+	next  int
+	full  bool
+}
+
+func (r *ringBuffer) add(p []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lines[r.next] = append(r.lines[r.next][:0], p...)
+	r.next = (r.next + 1) % len(r.lines)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+func (r *ringBuffer) snapshot() [][]byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var out [][]byte
+	if r.full {
+		out = append(out, r.lines[r.next:]...)
+	}
+	out = append(out, r.lines[:r.next]...)
+
+	copied := make([][]byte, len(out))
+	for i, line := range out {
+		copied[i] = append([]byte(nil), line...)
+	}
+
+	return copied
+}
+
+// ringWriter records every line in ring before passing it on to out.
+type ringWriter struct {
+	out  io.Writer
+	ring *ringBuffer
+}
+
+func (w *ringWriter) Write(p []byte) (int, error) {
+	w.ring.add(p)
+
+	return w.out.Write(p)
+}
@@ -0,0 +1,67 @@
+package logruswrapper
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestRingBuffer_KeepsLastLines(t *testing.T) {
+	captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	EnableRingBuffer(3)
+	defer EnableRingBuffer(0)
+
+	ctx := context.Background()
+	for i := 1; i <= 5; i++ {
+		Info(ctx, fmt.Sprintf("line %d", i), nil)
+	}
+
+	dump := &bytes.Buffer{}
+	if err := DumpRingBuffer(dump); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(dump.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 retained lines, got %d: %s", len(lines), dump.String())
+	}
+	for i, want := range []string{"line 3", "line 4", "line 5"} {
+		if !strings.Contains(lines[i], `"msg":"`+want+`"`) {
+			t.Errorf("expected line %d to be %q, got %s", i, want, lines[i])
+		}
+	}
+}
+
+func TestRingBuffer_DumpedOnFatal(t *testing.T) {
+	captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	meta := &bytes.Buffer{}
+	metaOutput = meta
+	defer func() { metaOutput = os.Stderr }()
+
+	EnableRingBuffer(2)
+	defer EnableRingBuffer(0)
+	SetFatalPanics(true)
+	defer SetFatalPanics(false)
+
+	ctx := context.Background()
+	Info(ctx, "before the crash", nil)
+	func() {
+		defer func() { _ = recover() }()
+		Fatal(ctx, "crashing", nil)
+	}()
+
+	if !strings.Contains(meta.String(), "before the crash") || !strings.Contains(meta.String(), "crashing") {
+		t.Errorf("expected ring buffer dumped on Fatal, got %q", meta.String())
+	}
+}
@@ -0,0 +1,52 @@
+package logruswrapper
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// StartRuntimeStatsLogger logs goroutine, heap and GC statistics at Info
+// every interval until ctx is cancelled. The returned channel is closed once
+// the logger has stopped.
+func StartRuntimeStatsLogger(ctx context.Context, interval time.Duration) <-chan struct{} {
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				logRuntimeStats(ctx)
+			}
+		}
+	}()
+
+	return done
+}
+
+func logRuntimeStats(ctx context.Context) {
+	// ReadMemStats stops the world, so only pay for it when the line is
+	// written.
+	if !enabled(ctx, logrus.InfoLevel, "runtime stats") {
+		return
+	}
+
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	fields := Fields{
+		"goroutines": runtime.NumGoroutine(),
+		"heap_alloc": m.HeapAlloc,
+		"num_gc":     m.NumGC,
+	}
+	generateLogger(ctx, &fields).Info("runtime stats")
+}
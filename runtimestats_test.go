@@ -0,0 +1,92 @@
+package logruswrapper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// lockedBuffer is a bytes.Buffer safe for concurrent writes and reads.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestStartRuntimeStatsLogger(t *testing.T) {
+	captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	buf := &lockedBuffer{}
+	log.SetOutput(buf)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := StartRuntimeStatsLogger(ctx, 5*time.Millisecond)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(buf.String(), "runtime stats") && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected stats logger to stop after cancellation")
+	}
+
+	line := strings.SplitN(strings.TrimSpace(buf.String()), "\n", 2)[0]
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		t.Fatalf("expected at least one valid JSON stats line: %v", err)
+	}
+	for _, key := range []string{"goroutines", "heap_alloc", "num_gc"} {
+		if _, ok := entry[key]; !ok {
+			t.Errorf("expected %s field in stats line", key)
+		}
+	}
+
+	// No further lines may be written once stopped.
+	stopped := buf.String()
+	time.Sleep(20 * time.Millisecond)
+	if buf.String() != stopped {
+		t.Error("expected no stats lines after shutdown")
+	}
+}
+
+func TestStartRuntimeStatsLogger_SuppressedBelowInfo(t *testing.T) {
+	captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.ErrorLevel)
+
+	buf := &lockedBuffer{}
+	log.SetOutput(buf)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := StartRuntimeStatsLogger(ctx, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	<-done
+
+	if buf.String() != "" {
+		t.Errorf("expected no stats lines at Error level, got %q", buf.String())
+	}
+}
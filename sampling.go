@@ -0,0 +1,171 @@
+package logruswrapper
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	sampling atomic.Pointer[samplingConfig]
+	// samplingCounts maps a request ID to its *requestSampling.
+	samplingCounts sync.Map
+	// samplingSweptAt is when stale requests were last pruned, in Unix
+	// nanoseconds.
+	samplingSweptAt atomic.Int64
+
+	// samplingTTL is how long a request's counters are kept after its last
+	// line when FlushRequestSampling is never called for it.
+	samplingTTL = 10 * time.Minute
+)
+
+type samplingConfig struct {
+	key interface{}
+	max int
+}
+
+// requestSampling counts the lines of one request per message.
+type requestSampling struct {
+	mu       sync.Mutex
+	lastSeen time.Time
+	msgs     map[string]*sampledMessage
+}
+
+type sampledMessage struct {
+	level logrus.Level
+	seen  int
+}
+
+// SetPerRequestSampling limits identical messages to max per request, where
+// the request ID is the context value stored under key. Suppressed lines are
+// summarized by FlushRequestSampling, which should be called when the
+// request ends; counters of requests that are never flushed are dropped
+// without a summary once they have been idle for ten minutes. Event and
+// LogSQL lines are counted per event name and per query. A max <= 0
+// disables sampling.
+func SetPerRequestSampling(key interface{}, max int) {
+	samplingCounts.Clear()
+	samplingSweptAt.Store(0)
+	if key == nil || max <= 0 {
+		sampling.Store(nil)
+		return
+	}
+	sampling.Store(&samplingConfig{key: key, max: max})
+}
+
+// sampleAllow reports whether msg may be logged for the request on ctx. It
+// must only be called for lines whose level is enabled.
+func sampleAllow(ctx context.Context, level logrus.Level, msg string) bool {
+	cfg := sampling.Load()
+	if cfg == nil || ctx == nil {
+		return true
+	}
+	reqID := ctx.Value(cfg.key)
+	if reqID == nil {
+		return true
+	}
+
+	t := now()
+	sweepSampling(t)
+
+	id := fmt.Sprint(reqID)
+	v, ok := samplingCounts.Load(id)
+	if !ok {
+		v, _ = samplingCounts.LoadOrStore(id, &requestSampling{msgs: map[string]*sampledMessage{}})
+	}
+	r := v.(*requestSampling)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastSeen = t
+	m := r.msgs[msg]
+	if m == nil {
+		m = &sampledMessage{level: level}
+		r.msgs[msg] = m
+	}
+	m.seen++
+
+	return m.seen <= cfg.max
+}
+
+// sampleKey returns the key a helper's line is counted under by per-request
+// sampling. Helpers log a fixed message, so detail (an event name, a query)
+// is added to keep their lines from sharing one counter.
+func sampleKey(msg, detail string) string {
+	if sampling.Load() == nil || detail == "" {
+		return msg
+	}
+
+	return msg + ": " + detail
+}
+
+// sweepSampling drops the counters of requests idle for samplingTTL, at
+// most once per samplingTTL.
+func sweepSampling(t time.Time) {
+	last := samplingSweptAt.Load()
+	if t.UnixNano()-last < int64(samplingTTL) || !samplingSweptAt.CompareAndSwap(last, t.UnixNano()) {
+		return
+	}
+
+	samplingCounts.Range(func(id, v interface{}) bool {
+		r := v.(*requestSampling)
+		r.mu.Lock()
+		stale := t.Sub(r.lastSeen) >= samplingTTL
+		r.mu.Unlock()
+		if stale {
+			samplingCounts.CompareAndDelete(id, v)
+		}
+		return true
+	})
+}
+
+// FlushRequestSampling logs one "suppressed N more" line per message that
+// hit the limit for the request on ctx and forgets the request.
+func FlushRequestSampling(ctx context.Context) {
+	cfg := sampling.Load()
+	if cfg == nil || ctx == nil {
+		return
+	}
+	reqID := ctx.Value(cfg.key)
+	if reqID == nil {
+		return
+	}
+	v, ok := samplingCounts.LoadAndDelete(fmt.Sprint(reqID))
+	if !ok {
+		return
+	}
+	// Swap the map out so lines still being counted on r, which is no
+	// longer reachable from samplingCounts, cannot touch the one read here.
+	r := v.(*requestSampling)
+	r.mu.Lock()
+	msgs := r.msgs
+	r.msgs = map[string]*sampledMessage{}
+	r.mu.Unlock()
+	limit := cfg.max
+	level := levelFor(ctx, callerPC(1))
+
+	keys := make([]string, 0, len(msgs))
+	for msg := range msgs {
+		keys = append(keys, msg)
+	}
+	sort.Strings(keys)
+
+	for _, msg := range keys {
+		m := msgs[msg]
+		if m.seen <= limit || level < m.level {
+			continue
+		}
+		suppressed := m.seen - limit
+		fields := Fields{
+			"suppressed_msg":   msg,
+			"suppressed_count": suppressed,
+		}
+		generateLogger(ctx, &fields).Log(m.level, fmt.Sprintf("suppressed %d more", suppressed))
+	}
+}
@@ -0,0 +1,142 @@
+package logruswrapper
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type sampleReqKey struct{}
+
+func TestSetPerRequestSampling_CapsPerRequest(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	SetPerRequestSampling(sampleReqKey{}, 3)
+	defer SetPerRequestSampling(nil, 0)
+
+	reqA := context.WithValue(context.Background(), sampleReqKey{}, "req-a")
+	reqB := context.WithValue(context.Background(), sampleReqKey{}, "req-b")
+	for i := 0; i < 10; i++ {
+		Warn(reqA, "retrying item", nil)
+	}
+	Warn(reqB, "retrying item", nil)
+	Info(reqA, "different message", nil)
+	for i := 0; i < 5; i++ {
+		Info(context.Background(), "no request", nil)
+	}
+
+	counts := map[string]int{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("expected valid JSON output: %v", err)
+		}
+		counts[entry["msg"].(string)]++
+	}
+
+	if counts["retrying item"] != 4 {
+		t.Errorf("expected 3 lines for req-a plus 1 for req-b, got %d", counts["retrying item"])
+	}
+	if counts["different message"] != 1 {
+		t.Errorf("expected other messages to be unaffected, got %d", counts["different message"])
+	}
+	if counts["no request"] != 5 {
+		t.Errorf("expected lines without a request ID to be unaffected, got %d", counts["no request"])
+	}
+
+	buf.Reset()
+	FlushRequestSampling(reqA)
+
+	var summary map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &summary); err != nil {
+		t.Fatalf("expected a single summary line, got %v: %s", err, buf.String())
+	}
+	if summary["msg"] != "suppressed 7 more" {
+		t.Errorf("expected 'suppressed 7 more', got %v", summary["msg"])
+	}
+	if summary["suppressed_msg"] != "retrying item" || summary["level"] != "warning" {
+		t.Errorf("unexpected summary line: %v", summary)
+	}
+
+	buf.Reset()
+	Warn(reqA, "retrying item", nil)
+	if buf.Len() == 0 {
+		t.Error("expected the request's counters to be reset after flushing")
+	}
+}
+
+func TestSetPerRequestSampling_SkipsAudit(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	SetPerRequestSampling(sampleReqKey{}, 2)
+	defer SetPerRequestSampling(nil, 0)
+
+	ctx := context.WithValue(context.Background(), sampleReqKey{}, "req-helpers")
+	defer FlushRequestSampling(ctx)
+	for i := 0; i < 5; i++ {
+		if err := Audit(ctx, "delete", Fields{"actor": "u1", "resource": "doc", "outcome": "ok"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if n := strings.Count(buf.String(), `"audit":true`); n != 5 {
+		t.Errorf("expected every Audit line to be written, got %d", n)
+	}
+}
+
+func TestSetPerRequestSampling_HelpersCountedPerName(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.DebugLevel)
+
+	SetPerRequestSampling(sampleReqKey{}, 1)
+	defer SetPerRequestSampling(nil, 0)
+
+	ctx := context.WithValue(context.Background(), sampleReqKey{}, "req-names")
+	defer FlushRequestSampling(ctx)
+	Event(ctx, "signup", nil)
+	Event(ctx, "checkout", nil)
+	Event(ctx, "checkout", nil)
+	LogSQL(ctx, "SELECT 1", nil, time.Millisecond, nil)
+	LogSQL(ctx, "SELECT 2", nil, time.Millisecond, nil)
+
+	out := buf.String()
+	if strings.Count(out, `"event":"signup"`) != 1 || strings.Count(out, `"event":"checkout"`) != 1 {
+		t.Errorf("expected one line per event name, got %s", out)
+	}
+	if strings.Count(out, `"msg":"sql query"`) != 2 {
+		t.Errorf("expected distinct queries to be counted separately, got %s", out)
+	}
+}
+
+func TestSetPerRequestSampling_DropsIdleRequests(t *testing.T) {
+	captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	clock := time.Now()
+	now = func() time.Time { return clock }
+	defer func() { now = time.Now }()
+
+	SetPerRequestSampling(sampleReqKey{}, 3)
+	defer SetPerRequestSampling(nil, 0)
+
+	Info(context.WithValue(context.Background(), sampleReqKey{}, "req-forgotten"), "working", nil)
+	clock = clock.Add(samplingTTL + time.Second)
+	Info(context.WithValue(context.Background(), sampleReqKey{}, "req-live"), "working", nil)
+
+	if _, ok := samplingCounts.Load("req-forgotten"); ok {
+		t.Error("expected counters of an idle, never flushed request to be dropped")
+	}
+	if _, ok := samplingCounts.Load("req-live"); !ok {
+		t.Error("expected counters of the active request to be kept")
+	}
+}
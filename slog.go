@@ -0,0 +1,156 @@
+package logruswrapper
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// NewSlogHandler returns a slog.Handler that writes records through this
+// package's logger, so slog users share its formatter, output and hooks.
+// Attributes inside groups become dotted field names, e.g. "http.status".
+func NewSlogHandler() slog.Handler {
+	return &slogHandler{}
+}
+
+type slogHandler struct {
+	attrs  Fields
+	prefix string
+}
+
+func (h *slogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return levelFor(ctx, slogCallerPC()) >= slogToLogrusLevel(level)
+}
+
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	level := slogToLogrusLevel(r.Level)
+	if !enabledAt(ctx, r.PC, level, r.Message) {
+		return nil
+	}
+
+	fields := make(Fields, len(h.attrs)+r.NumAttrs())
+	for k, v := range h.attrs {
+		fields[k] = v
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		addSlogAttr(fields, h.prefix, a)
+		return true
+	})
+
+	entry := generateLogger(ctx, &fields).WithFields(slogCaller(r.PC))
+	if !r.Time.IsZero() {
+		entry = entry.WithTime(r.Time)
+	}
+	entry.Log(level, r.Message)
+	if level == logrus.ErrorLevel {
+		errorLogged(level)
+	}
+
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	fields := make(Fields, len(h.attrs)+len(attrs))
+	for k, v := range h.attrs {
+		fields[k] = v
+	}
+	for _, a := range attrs {
+		addSlogAttr(fields, h.prefix, a)
+	}
+
+	return &slogHandler{attrs: fields, prefix: h.prefix}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	return &slogHandler{attrs: h.attrs, prefix: h.prefix + name + "."}
+}
+
+// addSlogAttr stores a in fields under prefix, flattening groups into
+// dotted keys.
+func addSlogAttr(fields Fields, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			addSlogAttr(fields, prefix, ga)
+		}
+		return
+	}
+
+	fields[prefix+a.Key] = a.Value.Any()
+}
+
+func slogToLogrusLevel(level slog.Level) logrus.Level {
+	switch {
+	case level >= slog.LevelError:
+		return logrus.ErrorLevel
+	case level >= slog.LevelWarn:
+		return logrus.WarnLevel
+	case level >= slog.LevelInfo:
+		return logrus.InfoLevel
+	case level >= slog.LevelDebug:
+		return logrus.DebugLevel
+	}
+
+	return logrus.TraceLevel
+}
+
+// slogCallerPC returns the PC of the code that called into log/slog, for
+// Enabled, which slog calls before it has captured the record's PC.
+func slogCallerPC() uintptr {
+	if len(loadOptions().packageLevels) == 0 {
+		return 0
+	}
+
+	var pcs [16]uintptr
+	n := runtime.Callers(3, pcs[:])
+	for _, pc := range pcs[:n] {
+		if !strings.HasPrefix(callSiteFunction(pc), "log/slog.") {
+			return pc
+		}
+	}
+
+	return 0
+}
+
+// slogCaller builds the file/func fields from a record's PC, which slog
+// captures at the call site, honoring SetCallerFields.
+func slogCaller(pc uintptr) Fields {
+	o := loadOptions()
+	if pc == 0 || (!o.callerFile && !o.callerFunc) {
+		return Fields{}
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	fields := Fields{}
+	if o.callerFile {
+		file := frame.File
+		if lastSlash := strings.LastIndex(file, "/"); lastSlash >= 0 {
+			file = file[lastSlash+1:]
+		}
+		fields["file"] = fmt.Sprintf("%s:%d", file, frame.Line)
+	}
+	if o.callerFunc {
+		fields["func"] = frame.Function
+	}
+
+	return fields
+}
@@ -0,0 +1,57 @@
+package logruswrapper
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestSlogHandler_RoutesThroughWrapper(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	logger := slog.New(NewSlogHandler()).With("service", "api").WithGroup("http")
+	logger.WarnContext(context.Background(), "slow request",
+		"status", 200,
+		slog.Group("req", "method", "GET"),
+	)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	if entry["level"] != "warning" || entry["msg"] != "slow request" {
+		t.Errorf("expected warning 'slow request', got %v", entry)
+	}
+	if entry["service"] != "api" {
+		t.Errorf("expected ungrouped attr 'service', got %v", entry["service"])
+	}
+	if entry["http.status"] != float64(200) {
+		t.Errorf("expected grouped attr 'http.status', got %v", entry["http.status"])
+	}
+	if entry["http.req.method"] != "GET" {
+		t.Errorf("expected nested group attr 'http.req.method', got %v", entry["http.req.method"])
+	}
+	if file, _ := entry["file"].(string); !strings.HasPrefix(file, "slog_test.go:") {
+		t.Errorf("expected caller at the slog call site, got %v", entry["file"])
+	}
+}
+
+func TestSlogHandler_RespectsLevel(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	logger := slog.New(NewSlogHandler())
+	logger.Debug("hidden")
+
+	if buf.Len() != 0 {
+		t.Errorf("expected debug record to be suppressed, got %s", buf.String())
+	}
+}
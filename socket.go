@@ -0,0 +1,63 @@
+package logruswrapper
+
+import (
+	"net"
+	"sync"
+)
+
+// SetUnixSocketOutput writes lines to the Unix domain socket at path. A
+// failed write closes the connection and is retried once on a fresh one, so
+// a restarted collector is picked up without reconfiguring.
+func SetUnixSocketOutput(path string) error {
+	w := &unixSocketWriter{path: path}
+	if err := w.dial(); err != nil {
+		return err
+	}
+
+	SetOutput(w)
+
+	return nil
+}
+
+type unixSocketWriter struct {
+	path string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// dial connects to path. w.mu must be held or w not yet shared.
+func (w *unixSocketWriter) dial() error {
+	conn, err := net.Dial("unix", w.path)
+	if err != nil {
+		return err
+	}
+	w.conn = conn
+
+	return nil
+}
+
+func (w *unixSocketWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn != nil {
+		n, err := w.conn.Write(p)
+		if err == nil {
+			return n, nil
+		}
+		w.conn.Close()
+		w.conn = nil
+	}
+
+	if err := w.dial(); err != nil {
+		return 0, err
+	}
+	n, err := w.conn.Write(p)
+	if err != nil {
+		w.conn.Close()
+		w.conn = nil
+	}
+
+	return n, err
+}
@@ -0,0 +1,81 @@
+package logruswrapper
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestSetUnixSocketOutput_ReconnectsAfterDrop(t *testing.T) {
+	captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	dir, err := os.MkdirTemp("", "sock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "log.sock")
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	conns := make(chan net.Conn, 4)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conns <- conn
+		}
+	}()
+
+	if err := SetUnixSocketOutput(path); err != nil {
+		t.Fatalf("expected socket output to be set, got %v", err)
+	}
+	ctx := context.Background()
+
+	first := <-conns
+	Info(ctx, "first line", nil)
+	line, err := bufio.NewReader(first).ReadString('\n')
+	if err != nil || !strings.Contains(line, "first line") {
+		t.Fatalf("expected first line over the socket, got %q, %v", line, err)
+	}
+	first.Close()
+
+	var second net.Conn
+	deadline := time.After(5 * time.Second)
+	for second == nil {
+		Info(ctx, "after drop", nil)
+		select {
+		case second = <-conns:
+		case <-deadline:
+			t.Fatal("expected the writer to reconnect after the connection was dropped")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	defer second.Close()
+
+	line, err = bufio.NewReader(second).ReadString('\n')
+	if err != nil || !strings.Contains(line, "after drop") {
+		t.Errorf("expected line on the new connection, got %q, %v", line, err)
+	}
+}
+
+func TestSetUnixSocketOutput_DialError(t *testing.T) {
+	if err := SetUnixSocketOutput(filepath.Join(os.TempDir(), "missing-log.sock")); err == nil {
+		t.Error("expected an error when nothing listens on the socket")
+	}
+}
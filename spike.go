@@ -0,0 +1,67 @@
+package logruswrapper
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	spikeMu     sync.Mutex
+	spikeCount  int
+	spikeWindow time.Duration
+	spikeStart  time.Time
+	spikeSeen   int
+	spikeFired  bool
+
+	now = time.Now
+)
+
+// SetErrorSpikeThreshold emits one extra "error_spike" line per window once
+// more than count errors are logged within it. A count or window <= 0
+// disables detection.
+func SetErrorSpikeThreshold(count int, window time.Duration) {
+	spikeMu.Lock()
+	defer spikeMu.Unlock()
+
+	spikeCount = count
+	spikeWindow = window
+	spikeStart = time.Time{}
+	spikeSeen = 0
+	spikeFired = false
+}
+
+// recordError counts an emitted error and logs the spike line when the
+// threshold is first crossed within the current window.
+func recordError() {
+	spikeMu.Lock()
+	if spikeCount <= 0 || spikeWindow <= 0 {
+		spikeMu.Unlock()
+		return
+	}
+
+	t := now()
+	if spikeStart.IsZero() || t.Sub(spikeStart) >= spikeWindow {
+		spikeStart = t
+		spikeSeen = 0
+		spikeFired = false
+	}
+	spikeSeen++
+
+	fire := spikeSeen > spikeCount && !spikeFired
+	if fire {
+		spikeFired = true
+	}
+	rate := spikeSeen
+	window := spikeWindow
+	spikeMu.Unlock()
+
+	if fire && currentLevel() >= logrus.ErrorLevel {
+		log.WithFields(Fields{
+			"error_spike": true,
+			"rate":        rate,
+			"window":      window.String(),
+		}).Error("error spike detected")
+	}
+}
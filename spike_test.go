@@ -0,0 +1,82 @@
+package logruswrapper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func countSpikeLines(t *testing.T, out string) (int, float64) {
+	t.Helper()
+	spikes, rate := 0, 0.0
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("expected valid JSON output: %v", err)
+		}
+		if entry["error_spike"] == true {
+			spikes++
+			rate, _ = entry["rate"].(float64)
+		}
+	}
+	return spikes, rate
+}
+
+func TestSetErrorSpikeThreshold_OncePerWindow(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return clock }
+	defer func() { now = time.Now }()
+
+	SetErrorSpikeThreshold(3, time.Minute)
+	defer SetErrorSpikeThreshold(0, 0)
+
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		Error(ctx, "upstream failed", errors.New("timeout"), nil)
+		clock = clock.Add(time.Second)
+	}
+
+	spikes, rate := countSpikeLines(t, buf.String())
+	if spikes != 1 {
+		t.Fatalf("expected exactly 1 spike line in the first window, got %d", spikes)
+	}
+	if rate != 4 {
+		t.Errorf("expected rate 4 when the threshold is crossed, got %v", rate)
+	}
+
+	buf.Reset()
+	clock = clock.Add(time.Minute)
+	for i := 0; i < 4; i++ {
+		Error(ctx, "upstream failed", errors.New("timeout"), nil)
+	}
+
+	if spikes, _ := countSpikeLines(t, buf.String()); spikes != 1 {
+		t.Errorf("expected 1 spike line in the next window, got %d", spikes)
+	}
+}
+
+func TestSetErrorSpikeThreshold_BelowThreshold(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	SetErrorSpikeThreshold(5, time.Minute)
+	defer SetErrorSpikeThreshold(0, 0)
+
+	for i := 0; i < 5; i++ {
+		Error(context.Background(), "upstream failed", errors.New("timeout"), nil)
+	}
+
+	if spikes, _ := countSpikeLines(t, buf.String()); spikes != 0 {
+		t.Errorf("expected no spike line at the threshold, got %d", spikes)
+	}
+}
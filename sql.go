@@ -0,0 +1,61 @@
+package logruswrapper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LogSQL logs a query at Debug, or at Error when err is non-nil, with its
+// whitespace-normalized text, duration and masked arguments. Argument
+// values are never logged, only their type and, for strings and byte
+// slices, their length.
+func LogSQL(ctx context.Context, query string, args []interface{}, d time.Duration, err error) {
+	level, msg := logrus.DebugLevel, "sql query"
+	if err != nil {
+		level, msg = logrus.ErrorLevel, "sql query failed"
+	}
+	if !enabled(ctx, level, sampleKey(msg, query)) {
+		return
+	}
+
+	fields := Fields{
+		"query":       normalizeQuery(query),
+		"args":        maskArgs(args),
+		"duration_ms": d.Milliseconds(),
+	}
+
+	callerFields := getCaller(level)
+	entry := generateLogger(ctx, &fields).WithFields(*callerFields)
+	if err != nil {
+		logError(entry, msg, err)
+		return
+	}
+
+	entry.Debug(msg)
+}
+
+func normalizeQuery(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
+
+func maskArgs(args []interface{}) []string {
+	masked := make([]string, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case nil:
+			masked[i] = "nil"
+		case string:
+			masked[i] = fmt.Sprintf("string(len=%d)", len(v))
+		case []byte:
+			masked[i] = fmt.Sprintf("[]byte(len=%d)", len(v))
+		default:
+			masked[i] = fmt.Sprintf("%T", v)
+		}
+	}
+
+	return masked
+}
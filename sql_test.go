@@ -0,0 +1,70 @@
+package logruswrapper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestLogSQL_MasksArgs(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.DebugLevel)
+	defer setLevel(logrus.InfoLevel)
+
+	query := `SELECT id
+		FROM users
+		WHERE email = $1 AND age > $2`
+	LogSQL(context.Background(), query, []interface{}{"alice@example.com", 42, nil}, 15*time.Millisecond, nil)
+
+	if strings.Contains(buf.String(), "alice@example.com") || strings.Contains(buf.String(), "42,") {
+		t.Fatalf("expected argument values to be masked, got %s", buf.String())
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	if entry["level"] != "debug" {
+		t.Errorf("expected level 'debug', got %v", entry["level"])
+	}
+	if entry["query"] != "SELECT id FROM users WHERE email = $1 AND age > $2" {
+		t.Errorf("expected normalized query, got %v", entry["query"])
+	}
+	if entry["duration_ms"] != float64(15) {
+		t.Errorf("expected duration_ms 15, got %v", entry["duration_ms"])
+	}
+	args, _ := entry["args"].([]interface{})
+	want := []string{"string(len=17)", "int", "nil"}
+	if len(args) != len(want) {
+		t.Fatalf("expected %d masked args, got %v", len(want), entry["args"])
+	}
+	for i := range want {
+		if args[i] != want[i] {
+			t.Errorf("expected masked arg %q, got %v", want[i], args[i])
+		}
+	}
+}
+
+func TestLogSQL_ErrorLevelOnFailure(t *testing.T) {
+	buf := captureOutput()
+	defer restoreOutput()
+	setLevel(logrus.InfoLevel)
+
+	LogSQL(context.Background(), "DELETE FROM sessions", nil, time.Second, errors.New("deadlock detected"))
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected valid JSON output: %v", err)
+	}
+
+	if entry["level"] != "error" || entry["error"] != "deadlock detected" {
+		t.Errorf("expected error line with the error, got %v", entry)
+	}
+}
@@ -0,0 +1,73 @@
+// Package sqlmiddleware logs database/sql queries through logruswrapper by
+// wrapping a driver.Connector:
+//
+//	db := sql.OpenDB(sqlmiddleware.NewConnector(connector))
+//
+// Every query and exec is logged with logruswrapper.LogSQL, so arguments are
+// masked and the fields stored on the query's context are attached.
+package sqlmiddleware
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"time"
+
+	logruswrapper "github.com/nandhasuhendra/logrus-wrapper"
+)
+
+// NewConnector wraps c so every statement run through its connections is
+// logged. Everything else is delegated to c unchanged.
+func NewConnector(c driver.Connector) driver.Connector {
+	return &connector{inner: c}
+}
+
+type connector struct {
+	inner driver.Connector
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	inner, err := c.inner.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &conn{inner: inner}, nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return c.inner.Driver()
+}
+
+func logQuery(ctx context.Context, query string, args []driver.NamedValue, start time.Time, err error) {
+	if errors.Is(err, driver.ErrSkip) {
+		return
+	}
+
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	logruswrapper.LogSQL(ctx, query, values, time.Since(start), err)
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+
+	return named
+}
+
+func plainValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errors.New("sqlmiddleware: driver does not support named parameters")
+		}
+		values[i] = arg.Value
+	}
+
+	return values, nil
+}
@@ -0,0 +1,114 @@
+package sqlmiddleware
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	logruswrapper "github.com/nandhasuhendra/logrus-wrapper"
+	"github.com/sirupsen/logrus"
+)
+
+type fakeConnector struct{}
+
+func (fakeConnector) Connect(context.Context) (driver.Conn, error) { return fakeConn{}, nil }
+func (fakeConnector) Driver() driver.Driver                        { return fakeDriver{} }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{}, nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (fakeConn) Close() error                        { return nil }
+func (fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (fakeConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return &fakeRows{}, nil
+}
+
+type fakeRows struct{ done bool }
+
+func (*fakeRows) Columns() []string { return []string{"id"} }
+func (*fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(1)
+	return nil
+}
+
+func TestConnector_LogsQueryWithMaskedArgs(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logruswrapper.SetOutput(buf)
+	defer logruswrapper.SetOutput(os.Stdout)
+	if err := logruswrapper.Reconfigure("debug"); err != nil {
+		t.Fatal(err)
+	}
+	defer logruswrapper.Reconfigure("info")
+
+	db := sql.OpenDB(NewConnector(fakeConnector{}))
+	defer db.Close()
+
+	ctx := logruswrapper.ContextWithFields(context.Background(), logruswrapper.Fields{"request_id": "abc"})
+	var id int
+	if err := db.QueryRowContext(ctx, "SELECT id\n  FROM users WHERE name = ?", "alice").Scan(&id); err != nil {
+		t.Fatalf("expected the query to go through the wrapped driver, got %v", err)
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected a single JSON line, got %q: %v", buf.String(), err)
+	}
+
+	if entry["level"] != "debug" || entry["msg"] != "sql query" {
+		t.Errorf("expected debug 'sql query', got %v", entry)
+	}
+	if entry["query"] != "SELECT id FROM users WHERE name = ?" {
+		t.Errorf("expected normalized query, got %v", entry["query"])
+	}
+	args, _ := entry["args"].([]interface{})
+	if len(args) != 1 || args[0] != "string(len=5)" {
+		t.Errorf("expected masked args, got %v", entry["args"])
+	}
+	if _, ok := entry["duration_ms"].(float64); !ok {
+		t.Errorf("expected duration_ms, got %v", entry["duration_ms"])
+	}
+	if entry["request_id"] != "abc" {
+		t.Errorf("expected context fields, got %v", entry["request_id"])
+	}
+}
+
+func TestConnector_PackageLevelOverride(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logruswrapper.SetOutput(buf)
+	defer logruswrapper.SetOutput(os.Stdout)
+	if err := logruswrapper.Reconfigure("info"); err != nil {
+		t.Fatal(err)
+	}
+
+	logruswrapper.SetPackageLevel("github.com/nandhasuhendra/logrus-wrapper/sqlmiddleware", logrus.DebugLevel)
+	defer logruswrapper.ClearPackageLevel("github.com/nandhasuhendra/logrus-wrapper/sqlmiddleware")
+
+	db := sql.OpenDB(NewConnector(fakeConnector{}))
+	defer db.Close()
+
+	var id int
+	if err := db.QueryRowContext(context.Background(), "SELECT id FROM users").Scan(&id); err != nil {
+		t.Fatalf("expected the query to go through the wrapped driver, got %v", err)
+	}
+
+	if !bytes.Contains(buf.Bytes(), []byte(`"msg":"sql query"`)) {
+		t.Errorf("expected the Debug query line at the package's level, got %q", buf.String())
+	}
+}